@@ -1,6 +1,7 @@
 package balance
 
 import (
+	"context"
 	"fmt"
 	"met/cmd/account"
 	"met/database"
@@ -25,6 +26,7 @@ var (
 	accountName  *string
 	accountIndex *uint
 	networkName  *string
+	selectors    *string
 )
 
 func init() {
@@ -33,53 +35,83 @@ func init() {
 	accountName = balanceCmd.Flags().String("account", "", "account name")
 	accountIndex = balanceCmd.Flags().Uint("account-index", 0, "account index")
 	networkName = balanceCmd.Flags().String("network", "", "network name")
+	selectors = balanceCmd.Flags().String("accounts", "", "account selectors separated by comma, eg: tag:bots,group:airdrop[0..49],deployer[0..2],all (conflicts with --account)")
 }
 
 func getBalance(cmd *cobra.Command, args []string) {
 	var (
-		err    error
-		logger = utils.GetLogger("getBalance")
+		err      error
+		logger   = utils.GetLogger("getBalance")
+		accounts []database.Account
 	)
 
-	logger.Info().Msgf("query account: %v account index: %v", *accountName, *accountIndex)
-	account, err := database.QueryAccountOrCurrent(*accountName, *accountIndex)
-	utils.ExitWhenErr(logger, err, "query account: %v error: %v", *accountName, err)
+	if *selectors != "" {
+		utils.ExitWhen(logger, *accountName != "", "--accounts conflicts with --account")
+
+		logger.Info().Msgf("select accounts: %v", *selectors)
+		accounts, err = database.SelectAccounts(*selectors)
+		utils.ExitWhenErr(logger, err, "select accounts error: %v", err)
+	} else {
+		logger.Info().Msgf("query account: %v account index: %v", *accountName, *accountIndex)
+		account, err := database.QueryAccountOrCurrent(*accountName, *accountIndex)
+		utils.ExitWhenErr(logger, err, "query account: %v error: %v", *accountName, err)
+		accounts = append(accounts, *account)
+	}
 
 	logger.Info().Msgf("query network: %v", *networkName)
 	network, err := database.QueryNetworkOrCurrent(*networkName)
 	utils.ExitWhenErr(logger, err, "query ntwork: %v error: %v", *networkName, err)
 
-	ctx, cancel := utils.DefaultTimeoutContext()
+	ctx, cancel := context.WithTimeout(context.Background(), network.RequestTimeout())
 	defer cancel()
 
-	logger.Debug().Msgf("dial rpc: %v", network.Rpc)
-	client, err := ethclient.DialContext(ctx, network.Rpc)
-	utils.ExitWhenErr(logger, err, "dial rpc: %v error: %v", network.Rpc, err)
+	client, err := utils.DialRpcs(ctx, network.Endpoints(), network.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+
+	for i := range accounts {
+		err = showBalance(ctx, client, network, &accounts[i])
+		utils.ExitWhenErr(logger, err, "%v", err)
+	}
+}
+
+func showBalance(ctx context.Context, client *ethclient.Client, network *database.Network, account *database.Account) error {
+	logger := utils.GetLogger("showBalance")
 
 	accountDetails, err := types.AccountToDetails(account)
-	utils.ExitWhenErr(logger, err, "get account details error: %v", err)
+	if err != nil {
+		return fmt.Errorf("get account details error: %w", err)
+	}
 
 	addressStr, err := accountDetails.Address()
-	utils.ExitWhenErr(logger, err, "get account address error: %v", err)
+	if err != nil {
+		return fmt.Errorf("get account address error: %w", err)
+	}
 	address := common.HexToAddress(addressStr)
 
 	logger.Info().Msgf("query balance for address: %v", addressStr)
 	balance, err := client.BalanceAt(ctx, address, nil)
-	utils.ExitWhenErr(logger, err, "query account balance error: %v", err)
+	if err != nil {
+		return fmt.Errorf("query account balance error: %w", err)
+	}
 
 	humanBalance, err := utils.FormatUnits(balance.String(), utils.UnitEth)
-	utils.ExitWhenErr(logger, err, "format balance: %v error: %v", balance.String(), err)
+	if err != nil {
+		return fmt.Errorf("format balance: %v error: %w", balance.String(), err)
+	}
 
 	logger.Info().Msgf("query nonce for address: %v", addressStr)
 	nonce, err := client.PendingNonceAt(ctx, address)
-	utils.ExitWhenErr(logger, err, "query nonce error: %v", err)
+	if err != nil {
+		return fmt.Errorf("query nonce error: %w", err)
+	}
 
 	var balanceInfo []string
 	balanceInfo = append(balanceInfo, fmt.Sprintf("\nAccount: %v Account Index: %v\n", accountDetails.Name, accountDetails.CurrentIndex))
 	balanceInfo = append(balanceInfo, fmt.Sprintf("Address: %v Balance: %v %v\n", addressStr, humanBalance, network.Symbol))
 	balanceInfo = append(balanceInfo, fmt.Sprintf("Nonce: %v\n", nonce))
-	balanceInfo = append(balanceInfo, fmt.Sprintf("Address Link: %v\n", fmt.Sprintf("%v/address/%v", network.Explorer, addressStr)))
+	balanceInfo = append(balanceInfo, fmt.Sprintf("Address Link: %v\n", network.AddressLink(addressStr)))
 	info := strings.Join(balanceInfo, "")
 	logger.Info().Msg(info)
 
+	return nil
 }
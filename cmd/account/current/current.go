@@ -28,8 +28,9 @@ func init() {
 func showCurrentAccount(cmd *cobra.Command, args []string) {
 	logger := utils.GetLogger("showCurrentAccount")
 
-	current, err := database.CurrentAccount()
+	// profile或者MET_ACCOUNT设置了account时显示它
+	current, err := database.QueryAccountOrCurrent("", 0)
 	utils.ExitWhenErr(logger, err, "show current account error: %s", err)
 
-	account.ShowAccount(current, *insecure)
+	account.ShowAccount(*current, *insecure)
 }
@@ -0,0 +1,96 @@
+package edit
+
+import (
+	"met/cmd/account"
+	"met/database"
+	hd "met/hd"
+	types "met/types"
+	utils "met/utils"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "edit account",
+	Long:  "rename account or edit its metadata, only the given flags are changed",
+	Run:   editAccount,
+}
+
+var (
+	name       *string
+	newName    *string
+	pathFormat *string
+	passphrase *string
+	tags       *string
+	groups     *string
+)
+
+func init() {
+	account.AccountCmd.AddCommand(editCmd)
+
+	name = editCmd.Flags().String("name", "", "account name")
+	newName = editCmd.Flags().String("new-name", "", "new account name")
+	pathFormat = editCmd.Flags().String("path-format", "", "bip32 path format,eg m/44'/60'/0'/0/x (x is placeholder)")
+	passphrase = editCmd.Flags().String("passphrase", "", "bip32 passphrase")
+	tags = editCmd.Flags().String("tags", "", "tags separated by comma")
+	groups = editCmd.Flags().String("groups", "", "groups separated by comma")
+}
+
+func editAccount(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("editAccount")
+
+	utils.ExitWhen(logger, *name == "", "need name")
+
+	acc, err := database.QueryAccount(*name)
+	utils.ExitWhenErr(logger, err, "query account: %v error: %s", *name, err)
+
+	updates := make(map[string]any)
+
+	if cmd.Flags().Changed("path-format") {
+		utils.ExitWhen(logger, acc.Type != types.MnemonicType, "path format only works for mnemonic account")
+		err = hd.CheckHdPath(*pathFormat)
+		utils.ExitWhenErr(logger, err, "invalid hd path: %s", err)
+		updates["path_format"] = *pathFormat
+	}
+
+	if cmd.Flags().Changed("passphrase") {
+		utils.ExitWhen(logger, acc.Type != types.MnemonicType, "passphrase only works for mnemonic account")
+		updates["passphrase"] = *passphrase
+	}
+
+	if cmd.Flags().Changed("tags") {
+		updates["tags"] = normalizeList(*tags)
+	}
+
+	if cmd.Flags().Changed("groups") {
+		updates["groups"] = normalizeList(*groups)
+	}
+
+	utils.ExitWhen(logger, len(updates) == 0 && *newName == "", "nothing to edit")
+
+	err = database.UpdateAccount(*name, *newName, updates)
+	utils.ExitWhenErr(logger, err, "edit account error: %s", err)
+
+	resultName := *name
+	if *newName != "" {
+		resultName = *newName
+	}
+	edited, err := database.QueryAccount(resultName)
+	utils.ExitWhenErr(logger, err, "query account: %v error: %s", resultName, err)
+
+	account.ShowAccount(edited, false)
+}
+
+// normalizeList 去掉逗号分隔列表中的空白和空项
+func normalizeList(list string) string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, ",")
+}
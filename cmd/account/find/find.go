@@ -0,0 +1,57 @@
+package find
+
+import (
+	"fmt"
+	"met/cmd/account"
+	database "met/database"
+	"met/types"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find <address>",
+	Short: "find which account controls an address",
+	Long:  "find which account controls an address, search unlocked private key accounts and mnemonic accounts up to --depth indexes",
+	Args:  cobra.ExactArgs(1),
+	Run:   findAccount,
+}
+
+var (
+	depth    *uint
+	doSwitch *bool
+)
+
+func init() {
+	account.AccountCmd.AddCommand(findCmd)
+
+	depth = findCmd.Flags().Uint("depth", 1000, "how many indexes to derive for mnemonic accounts")
+	doSwitch = findCmd.Flags().Bool("switch", false, "switch to the found account without asking")
+}
+
+func findAccount(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("findAccount")
+
+	logger.Info().Msgf("find address: %v depth: %v", args[0], *depth)
+	found, err := types.FindAccount(args[0], *depth)
+	utils.ExitWhenErr(logger, err, "find account error: %s", err)
+	utils.ExitWhen(logger, len(found) == 0, "address: %v not found in unlocked accounts (depth: %v)", args[0], *depth)
+
+	for _, f := range found {
+		logger.Info().Msgf("found account: %v type: %v index: %v path: %v", f.Name, f.Type, f.Index, f.Path)
+	}
+
+	target := found[0]
+	if !*doSwitch {
+		input, err := utils.ReadChar(fmt.Sprintf("Switch to account %v index %v ? [y/N] ", target.Name, target.Index))
+		utils.ExitWhenErr(logger, err, "read input error: %s", err)
+		if input != 'y' {
+			return
+		}
+	}
+
+	err = database.SwitchAccount(target.Name, int(target.Index))
+	utils.ExitWhenErr(logger, err, "switch account error: %s", err)
+	logger.Info().Msgf("switched to account: %v index: %v", target.Name, target.Index)
+}
@@ -22,6 +22,8 @@ var (
 	count   *uint
 
 	insecure *bool
+
+	selectors *string
 )
 
 func init() {
@@ -35,11 +37,23 @@ func init() {
 	count = listCmd.Flags().Uint("count", 1, "sub account count")
 
 	insecure = listCmd.Flags().Bool("insecure", false, "show private key or mnemonic")
+
+	selectors = listCmd.Flags().String("accounts", "", "account selectors separated by comma, eg: tag:bots,group:airdrop[0..49],deployer[0..2],all")
 }
 
 func listAccount(cmd *cobra.Command, args []string) {
 	logger := utils.GetLogger("listAccount")
-	if *name != "" {
+	if *selectors != "" {
+		utils.ExitWhen(logger, *name != "", "--accounts conflicts with --name")
+
+		accounts, err := database.SelectAccounts(*selectors)
+		utils.ExitWhenErr(logger, err, "select accounts error: %s", err)
+
+		for i := range accounts {
+			account.ShowAccount(accounts[i], *insecure)
+		}
+
+	} else if *name != "" {
 		acc, err := database.QueryAccount(*name)
 		utils.ExitWhenErr(logger, err, "query account: %s error: %s", *name, err)
 
@@ -1,6 +1,7 @@
 package lock
 
 import (
+	"fmt"
 	"met/cmd/account"
 	"met/database"
 	utils "met/utils"
@@ -12,14 +13,15 @@ var lockCmd = &cobra.Command{
 	Use:     "lock",
 	Aliases: []string{"l"},
 	Short:   "lock or unlock account",
-	Long:    "lock or unlock account",
+	Long:    "lock or unlock account, every account has its own password",
 	Run:     lockAccount,
 }
 
 var (
-	name     *string
-	unlock   *bool
-	password *string
+	name      *string
+	selectors *string
+	unlock    *bool
+	password  *string
 )
 
 func init() {
@@ -27,28 +29,66 @@ func init() {
 
 	// 指定名称时,只lock该账号信息,而不是所有账号信息
 	name = lockCmd.Flags().String("name", "", "lock specify account instead of all accounts")
+	selectors = lockCmd.Flags().String("accounts", "", "account selectors separated by comma, eg: tag:bots,group:airdrop (conflicts with --name)")
 
 	unlock = lockCmd.Flags().BoolP("unlock", "u", false, "unlock account")
-	password = lockCmd.Flags().String("password", "", "password")
+	password = lockCmd.Flags().String("password", "", "password (prompt for every account if empty)")
 }
 
 func lockAccount(cmd *cobra.Command, args []string) {
 	var (
-		logger = utils.GetLogger("listAccount")
-		err    error
+		logger      = utils.GetLogger("lockAccount")
+		err         error
+		accountList []database.Account
 	)
 
-	if *password == "" {
-		// read from stdin
-		*password, err = utils.ReadSecret("Enter password:")
-		utils.ExitWhenErr(logger, err, "read password error: %s", err)
-	}
+	if *selectors != "" {
+		utils.ExitWhen(logger, *name != "", "--accounts conflicts with --name")
+
+		selected, err := database.SelectAccounts(*selectors)
+		utils.ExitWhenErr(logger, err, "select accounts error: %s", err)
 
-	if *unlock {
-		err = database.UnlockAccount(*name, *password)
+		// lock与HD index无关，去掉重复的账户
+		seen := make(map[string]bool)
+		for _, acc := range selected {
+			if !seen[acc.Name] {
+				seen[acc.Name] = true
+				accountList = append(accountList, acc)
+			}
+		}
+	} else if *name != "" {
+		acc, err := database.QueryAccount(*name)
+		utils.ExitWhenErr(logger, err, "query account: %v error: %s", *name, err)
+		accountList = append(accountList, acc)
 	} else {
-		err = database.LockAccount(*name, *password)
+		accountList, err = database.QueryAllAccounts()
+		utils.ExitWhenErr(logger, err, "query accounts error: %s", err)
 	}
-	utils.ExitWhenErr(logger, err, "(un)lock account error: %s", err)
 
+	for _, acc := range accountList {
+		if acc.Encrypted != *unlock {
+			continue
+		}
+
+		pw := *password
+		if pw == "" {
+			// read from stdin
+			pw, err = utils.ReadSecret(fmt.Sprintf("Enter password of account %v:", acc.Name))
+			utils.ExitWhenErr(logger, err, "read password error: %s", err)
+
+			if !*unlock && acc.PasswordHash == "" {
+				// 第一次设置密码，需要确认
+				pw2, err := utils.ReadSecret(fmt.Sprintf("Repeat password of account %v:", acc.Name))
+				utils.ExitWhenErr(logger, err, "read password error: %s", err)
+				utils.ExitWhen(logger, pw != pw2, "password mismatch")
+			}
+		}
+
+		if *unlock {
+			err = database.UnlockAccount(acc.Name, pw)
+		} else {
+			err = database.LockAccount(acc.Name, pw)
+		}
+		utils.ExitWhenErr(logger, err, "(un)lock account error: %s", err)
+	}
 }
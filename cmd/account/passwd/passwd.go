@@ -0,0 +1,53 @@
+package passwd
+
+import (
+	"met/cmd/account"
+	"met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var passwdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "change account password",
+	Long:  "change account password, a locked account is re-encrypted under the new password",
+	Run:   changePassword,
+}
+
+var (
+	name *string
+)
+
+func init() {
+	account.AccountCmd.AddCommand(passwdCmd)
+
+	name = passwdCmd.Flags().String("name", "", "account name")
+}
+
+func changePassword(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("changePassword")
+
+	utils.ExitWhen(logger, *name == "", "need name")
+
+	acc, err := database.QueryAccount(*name)
+	utils.ExitWhenErr(logger, err, "query account: %v error: %s", *name, err)
+
+	var oldPassword string
+	if acc.Encrypted || acc.PasswordHash != "" {
+		oldPassword, err = utils.ReadSecret("Enter old password:")
+		utils.ExitWhenErr(logger, err, "read password error: %s", err)
+	}
+
+	newPassword, err := utils.ReadSecret("Enter new password:")
+	utils.ExitWhenErr(logger, err, "read password error: %s", err)
+	newPassword2, err := utils.ReadSecret("Repeat new password:")
+	utils.ExitWhenErr(logger, err, "read password error: %s", err)
+	utils.ExitWhen(logger, newPassword != newPassword2, "password mismatch")
+	utils.ExitWhen(logger, newPassword == "", "empty password")
+
+	err = database.ChangeAccountPassword(*name, oldPassword, newPassword)
+	utils.ExitWhenErr(logger, err, "change password error: %s", err)
+
+	logger.Info().Msgf("password of account: %v changed", *name)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package audit
+
+import (
+	"fmt"
+	cmd "met/cmd"
+	database "met/database"
+	utils "met/utils"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AuditCmd represents the audit command
+var AuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "signing audit log",
+	Long:  `query, verify and export the tamper-evident log of every signature produced by met`,
+	Run:   nil,
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(AuditCmd)
+
+	AuditCmd.PersistentFlags().String("account", "", "filter by account name")
+	AuditCmd.PersistentFlags().String("network", "", "filter by network name")
+	AuditCmd.PersistentFlags().String("since", "", "filter by time, eg: 2024-01-02 or 2024-01-02T15:04:05Z")
+}
+
+// QueryLogs 根据AuditCmd的公共flag查询日志
+func QueryLogs(cmd *cobra.Command, limit int) ([]database.SigningLog, error) {
+	account := cmd.Flag("account").Value.String()
+	network := cmd.Flag("network").Value.String()
+	sinceStr := cmd.Flag("since").Value.String()
+
+	var since time.Time
+	if sinceStr != "" {
+		var err error
+		since, err = utils.ParseTime(sinceStr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return database.QuerySigningLogs(account, network, since, limit)
+}
+
+func ShowSigningLog(l database.SigningLog) {
+	fmt.Printf("Entry %d\n", l.ID)
+	fmt.Printf("Time: %s\n", l.Time().Format(time.RFC3339))
+	fmt.Printf("Kind: %s\n", l.Kind)
+	fmt.Printf("Account: %s (index: %d)\n", l.AccountName, l.AccountIndex)
+	fmt.Printf("Network: %s (chainId: %s)\n", l.Network, l.ChainId)
+	fmt.Printf("Summary: %s\n", l.Summary)
+	fmt.Printf("Tx Hash: %s\n", l.TxHash)
+	fmt.Printf("Outcome: %s\n", l.Outcome)
+	fmt.Printf("Hash: %s\n", l.Hash)
+	fmt.Println()
+}
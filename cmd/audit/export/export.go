@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"met/cmd/audit"
+	"met/config"
+	database "met/database"
+	utils "met/utils"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export signing log",
+	Long:  "export signing log as csv or json",
+	Run:   exportLogs,
+}
+
+var (
+	format *string
+	output *string
+)
+
+func init() {
+	audit.AuditCmd.AddCommand(exportCmd)
+
+	format = exportCmd.Flags().String("format", "csv", "export format: csv json")
+	output = exportCmd.Flags().String("output", "", "output file, stdout if empty")
+}
+
+func exportLogs(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("exportLogs")
+
+	*format = config.Format(cmd.Flags(), *format, "csv", "json")
+	utils.ExitWhen(logger, *format != "csv" && *format != "json", "invalid format: %v", *format)
+
+	logs, err := audit.QueryLogs(cmd, 0)
+	utils.ExitWhenErr(logger, err, "query signing log error: %s", err)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		utils.ExitWhenErr(logger, err, "create file: %v error: %s", *output, err)
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "csv":
+		err = writeCsv(w, logs)
+	case "json":
+		err = writeJson(w, logs)
+	}
+	utils.ExitWhenErr(logger, err, "export signing log error: %s", err)
+
+	if *output != "" {
+		logger.Info().Msgf("%d entries exported to %v", len(logs), *output)
+	}
+}
+
+func writeCsv(w io.Writer, logs []database.SigningLog) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"id", "time", "kind", "account", "account_index", "network", "chain_id", "summary", "tx_hash", "outcome", "prev_hash", "hash"})
+	if err != nil {
+		return err
+	}
+
+	for _, l := range logs {
+		err = cw.Write([]string{
+			fmt.Sprintf("%d", l.ID),
+			l.Time().UTC().Format(time.RFC3339Nano),
+			l.Kind,
+			l.AccountName,
+			fmt.Sprintf("%d", l.AccountIndex),
+			l.Network,
+			l.ChainId,
+			l.Summary,
+			l.TxHash,
+			l.Outcome,
+			l.PrevHash,
+			l.Hash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+type jsonEntry struct {
+	ID           uint   `json:"id"`
+	Time         string `json:"time"`
+	Timestamp    int64  `json:"timestamp"`
+	Kind         string `json:"kind"`
+	Account      string `json:"account"`
+	AccountIndex uint   `json:"accountIndex"`
+	Network      string `json:"network"`
+	ChainId      string `json:"chainId"`
+	Summary      string `json:"summary"`
+	TxHash       string `json:"txHash"`
+	Outcome      string `json:"outcome"`
+	PrevHash     string `json:"prevHash"`
+	Hash         string `json:"hash"`
+}
+
+func writeJson(w io.Writer, logs []database.SigningLog) error {
+	entries := make([]jsonEntry, 0, len(logs))
+	for _, l := range logs {
+		entries = append(entries, jsonEntry{
+			ID:           l.ID,
+			Time:         l.Time().UTC().Format(time.RFC3339Nano),
+			Timestamp:    l.Timestamp,
+			Kind:         l.Kind,
+			Account:      l.AccountName,
+			AccountIndex: l.AccountIndex,
+			Network:      l.Network,
+			ChainId:      l.ChainId,
+			Summary:      l.Summary,
+			TxHash:       l.TxHash,
+			Outcome:      l.Outcome,
+			PrevHash:     l.PrevHash,
+			Hash:         l.Hash,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
@@ -0,0 +1,35 @@
+package list
+
+import (
+	"met/cmd/audit"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"show"},
+	Short:   "list signing log",
+	Long:    "list signing log",
+	Run:     listLogs,
+}
+
+var limit *int
+
+func init() {
+	audit.AuditCmd.AddCommand(listCmd)
+
+	limit = listCmd.Flags().Int("limit", 20, "show latest N entries (0 for all)")
+}
+
+func listLogs(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("listLogs")
+
+	logs, err := audit.QueryLogs(cmd, *limit)
+	utils.ExitWhenErr(logger, err, "query signing log error: %s", err)
+
+	for i := range logs {
+		audit.ShowSigningLog(logs[i])
+	}
+}
@@ -0,0 +1,29 @@
+package verify
+
+import (
+	"met/cmd/audit"
+	database "met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "verify signing log hash chain",
+	Long:  "verify the hash chain of signing log to detect modified, removed or reordered entries",
+	Run:   verifyLogs,
+}
+
+func init() {
+	audit.AuditCmd.AddCommand(verifyCmd)
+}
+
+func verifyLogs(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("verifyLogs")
+
+	n, err := database.VerifySigningLogs()
+	utils.ExitWhenErr(logger, err, "signing log tampered (%d entries ok before): %s", n, err)
+
+	logger.Info().Msgf("signing log ok, %d entries verified", n)
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	cmd "met/cmd"
 	database "met/database"
 	transaction "met/transaction"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/spf13/cobra"
@@ -183,7 +185,7 @@ func ReadContract(ctx context.Context, client *ethclient.Client, net *database.N
 	logger.Debug().Msgf("network info: %v", net)
 
 	logger.Info().Msgf("network: %v", net.Name)
-	logger.Info().Msgf("dial rpc: %v", net.Rpc)
+	logger.Info().Msgf("dial rpc: %v", utils.DisplayRpcUrl(net.Rpc))
 
 	logger.Info().Msg("prepare abi args")
 	methodName, _, realArgs, err := transaction.AbiArgs(abiObj, methodName, args...)
@@ -210,7 +212,8 @@ func ReadContract(ctx context.Context, client *ethclient.Client, net *database.N
 	return outputs, nil
 }
 
-func WriteContract(ctx context.Context, client *ethclient.Client, net *database.Network, accountDetails *types.AccountDetails, contract, abiJson, methodName, accountName, nonce, value, gasLimitRatio, gasLimit, gasRatio, gasPrice, gasFeeCap, gasTipCap string, accountIndex uint, eip1559 bool, noconfirm bool, args ...string) error {
+// WriteContract 发送之前在pending区块上模拟调用，simulateOnly为true时模拟之后返回
+func WriteContract(ctx context.Context, client *ethclient.Client, net *database.Network, accountDetails *types.AccountDetails, contract, abiJson, methodName, accountName, nonce, value, gasLimitRatio, gasLimit, gasRatio, gasPrice, gasFeeCap, gasTipCap string, accountIndex uint, eip1559 bool, noconfirm bool, overridePolicy bool, simulateOnly bool, args ...string) error {
 	logger := utils.GetLogger("WriteContract")
 
 	logger.Debug().Msgf("network info: %v", net)
@@ -235,16 +238,18 @@ func WriteContract(ctx context.Context, client *ethclient.Client, net *database.
 		return fmt.Errorf("create private key error: %w", err)
 	}
 
-	logger.Info().Msg("query chain id")
-	chainId, err := client.ChainID(ctx)
+	logger.Info().Msg("verify chain id")
+	chainId, err := transaction.VerifyChainId(ctx, client, net)
 	if err != nil {
-		return fmt.Errorf("get chain id error: %w", err)
+		return err
 	}
 
 	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, chainId)
 	if err != nil {
 		return err
 	}
+	var signedTx *ethTypes.Transaction
+	transactor.Signer = transaction.RecordingSigner(transaction.PolicySigner(accountDetails.Name, net.Name, overridePolicy, transactor.Signer), &signedTx)
 
 	logger.Info().Msgf("parse abi")
 	abiObj, err := transaction.ParseAbiJson(abiJson)
@@ -265,6 +270,22 @@ func WriteContract(ctx context.Context, client *ethclient.Client, net *database.
 		return fmt.Errorf("abi pack error: %v", err)
 	}
 
+	// 发送之前在pending区块上模拟调用
+	var simValue *big.Int
+	if value != "" {
+		simValue, err = utils.ParseUnits(value, utils.UnitEth)
+		if err != nil {
+			return fmt.Errorf("parse value: %v error: %w", value, err)
+		}
+	}
+	_, err = transaction.SimulateSend(client, net, addressStr, contract, simValue, input, abiJson, nil)
+	if err != nil {
+		return err
+	}
+	if simulateOnly {
+		return nil
+	}
+
 	txParams, err := transaction.GetTxParams(ctx, client, addressStr, contract, chainId.String(), nonce, value, gasLimitRatio, gasLimit, gasRatio, gasPrice, gasFeeCap, gasTipCap, eip1559, input)
 	if err != nil {
 		return fmt.Errorf("GetTxParams error: %w", err)
@@ -280,7 +301,9 @@ func WriteContract(ctx context.Context, client *ethclient.Client, net *database.
 	logger.Info().Msgf("From: %v", addressStr)
 	logger.Info().Msgf("To: %v (contract)", contract)
 	logger.Info().Msgf("Value: %v", value)
-	logger.Info().Msgf("Nonce: %v", transactor.Nonce)
+	if transactor.Nonce != nil {
+		logger.Info().Msgf("Nonce: %v", transactor.Nonce)
+	}
 	logger.Info().Msgf("GasLimit: %v", transactor.GasLimit)
 	logger.Info().Msgf("GasPrice: %v", transactor.GasPrice.String())
 	logger.Info().Msgf("GasFeeCap: %v", transactor.GasFeeCap.String())
@@ -301,24 +324,32 @@ func WriteContract(ctx context.Context, client *ethclient.Client, net *database.
 
 	}
 	boundContract := bind.NewBoundContract(contractAddress, *abiObj, client, client, nil)
-	tx, err := boundContract.Transact(transactor, methodName, realArgs...)
+	tx, err := transaction.TransactWithNonce(ctx, client, net.Name, transactor, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+		return boundContract.Transact(opts, methodName, realArgs...)
+	})
+	if signedTx != nil {
+		transaction.RecordTxSignature(accountDetails.Name, accountDetails.CurrentIndex, net.Name, signedTx, transaction.OutcomeSent, err)
+	}
 	if err != nil {
 		return fmt.Errorf("transact error: %v", err)
 	}
+	transaction.RecordSpend(accountDetails.Name, net.Name, tx)
+	transaction.RecordTransaction(accountDetails.Name, accountDetails.CurrentIndex, net.Name, tx)
 
-	ctx2, cancel2 := utils.DefaultTimeoutContext()
+	ctx2, cancel2 := context.WithTimeout(context.Background(), net.ReceiptTimeout())
 	defer cancel2()
 
 	logger.Info().Msgf("waiting for confirmation..")
-	receipt, err := bind.WaitMined(ctx2, client, tx)
+	receipt, err := transaction.WaitReceipt(ctx2, client, tx.Hash())
 	if err != nil {
 		return fmt.Errorf("get receipt for tx: %v error: %w", tx.Hash(), err)
 	}
+	transaction.RecordReceipt(receipt)
 
 	utils.ShowReceipt(logger, receipt)
 
 	logger.Info().Msgf("tx hash: %v", tx.Hash())
-	logger.Info().Msgf("tx url: %v", fmt.Sprintf("%v/tx/%v", net.Explorer, tx.Hash()))
+	logger.Info().Msgf("tx url: %v", net.TxLink(tx.Hash().Hex()))
 
 	return nil
 }
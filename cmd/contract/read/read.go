@@ -4,6 +4,7 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package read
 
 import (
+	"context"
 	"met/cmd/contract"
 	"met/consts"
 	"met/database"
@@ -65,9 +66,6 @@ func readContract(cmd *cobra.Command, args []string) {
 	utils.ExitWhen(logger, contractAddress == "", "missing contract")
 	utils.ExitWhen(logger, abi == "", "missing abi")
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	abiJson := abi
 	// built-in abi
 	switch abi {
@@ -87,7 +85,10 @@ func readContract(cmd *cobra.Command, args []string) {
 	net, err := database.QueryNetworkOrCurrent(network)
 	utils.ExitWhenErr(logger, err, "query network error: %v", err)
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 	defer client.Close()
 
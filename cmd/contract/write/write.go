@@ -4,6 +4,7 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package write
 
 import (
+	"context"
 	"met/cmd/contract"
 	"met/consts"
 	"met/database"
@@ -36,6 +37,9 @@ var (
 	eip1559       *bool
 
 	noconfirm *bool
+
+	overridePolicy *bool
+	simulateOnly   *bool
 )
 
 func init() {
@@ -67,6 +71,9 @@ func init() {
 	eip1559 = writeCmd.Flags().Bool("eip1559", true, "eip1559 (use --eip1559=false to disable)")
 	noconfirm = writeCmd.Flags().Bool("noconfirm", false, "noconfirm")
 
+	overridePolicy = writeCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+	simulateOnly = writeCmd.Flags().Bool("simulate-only", false, "run eth_call at pending block and exit without sending")
+
 }
 
 func writeContract(cmd *cobra.Command, args []string) {
@@ -82,9 +89,6 @@ func writeContract(cmd *cobra.Command, args []string) {
 	utils.ExitWhen(logger, contractAddress == "", "missing contract")
 	utils.ExitWhen(logger, abi == "", "missing abi")
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	abiJson := abi
 	// built-in abi
 	switch abi {
@@ -104,7 +108,10 @@ func writeContract(cmd *cobra.Command, args []string) {
 	net, err := database.QueryNetworkOrCurrent(network)
 	utils.ExitWhenErr(logger, err, "query network error: %v", err)
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 	defer client.Close()
 
@@ -114,6 +121,6 @@ func writeContract(cmd *cobra.Command, args []string) {
 	accountDetails, err := types.AccountToDetails(acc)
 	utils.ExitWhenErr(logger, err, "get account details error: %v", err)
 
-	err = contract.WriteContract(ctx, client, net, accountDetails, contractAddress, abiJson, method, *account, *nonce, *value, *gasLimitRatio, *gasLimit, *gasRatio, *gasPrice, *gasFeeCap, *gasTipCap, *accountIndex, *eip1559, *noconfirm, abiArgs...)
+	err = contract.WriteContract(ctx, client, net, accountDetails, contractAddress, abiJson, method, *account, *nonce, *value, *gasLimitRatio, *gasLimit, *gasRatio, *gasPrice, *gasFeeCap, *gasTipCap, *accountIndex, *eip1559, *noconfirm, *overridePolicy, *simulateOnly, abiArgs...)
 	utils.ExitWhenErr(logger, err, "write contract error: %v", err)
 }
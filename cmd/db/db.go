@@ -0,0 +1,22 @@
+package db
+
+import (
+	cmd "met/cmd"
+
+	"github.com/spf13/cobra"
+)
+
+// DbCmd 数据库schema管理，子命令启动时不自动执行migration
+var DbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "database schema",
+	Long:  `show and migrate the database schema version, met migrates automatically on start with a backup`,
+	Run:   nil,
+	Annotations: map[string]string{
+		cmd.SkipMigrateAnnotation: "true",
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(DbCmd)
+}
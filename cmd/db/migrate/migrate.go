@@ -0,0 +1,39 @@
+package migrate
+
+import (
+	"met/cmd/db"
+	database "met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "apply pending migrations",
+	Long:  "back up the database and apply pending migrations in order",
+	Run:   migrate,
+}
+
+var (
+	noBackup *bool
+)
+
+func init() {
+	db.DbCmd.AddCommand(migrateCmd)
+
+	noBackup = migrateCmd.Flags().Bool("no-backup", false, "do not back up the database before migrating")
+}
+
+func migrate(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("migrate")
+
+	_, applied, err := database.Migrate(!*noBackup)
+	utils.ExitWhenErr(logger, err, "migrate error: %s", err)
+
+	if len(applied) == 0 {
+		logger.Info().Msgf("database is up to date, schema version: %v", database.LatestSchemaVersion())
+		return
+	}
+	logger.Info().Msgf("applied %d migrations, schema version: %v", len(applied), applied[len(applied)-1].Version)
+}
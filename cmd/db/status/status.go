@@ -0,0 +1,44 @@
+package status
+
+import (
+	"fmt"
+	"met/cmd/db"
+	database "met/database"
+	utils "met/utils"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show schema version and migrations",
+	Long:  "show current schema version, applied and pending migrations",
+	Run:   showStatus,
+}
+
+func init() {
+	db.DbCmd.AddCommand(statusCmd)
+}
+
+func showStatus(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("showStatus")
+
+	current, statuses, err := database.SchemaStatus()
+	utils.ExitWhenErr(logger, err, "query schema version error: %s", err)
+
+	fmt.Printf("Schema Version: %d (latest: %d)\n\n", current, database.LatestSchemaVersion())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	w.Flush()
+}
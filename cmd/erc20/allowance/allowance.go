@@ -1,6 +1,7 @@
 package allowance
 
 import (
+	"context"
 	"met/cmd/erc20"
 	"met/database"
 	utils "met/utils"
@@ -40,13 +41,13 @@ func getAllowance(cmd *cobra.Command, args []string) {
 	utils.ExitWhen(logger, *owner == "", "missing owner address")
 	utils.ExitWhen(logger, *spender == "", "missing spender address")
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "query network error: %v", err)
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 
 	data, err := erc20.ReadErc20(ctx, *contract, client, net, erc20.Erc20Allowance, *owner, *spender)
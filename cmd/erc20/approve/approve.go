@@ -1,6 +1,7 @@
 package approve
 
 import (
+	"context"
 	"fmt"
 	"met/cmd/erc20"
 	"met/database"
@@ -28,6 +29,9 @@ var (
 	decimals *uint8
 
 	noconfirm *bool
+
+	overridePolicy *bool
+	simulateOnly   *bool
 )
 
 func init() {
@@ -43,6 +47,9 @@ func init() {
 	decimals = approveCmd.Flags().Uint8("decimals", 0, "token decimals")
 
 	noconfirm = approveCmd.Flags().Bool("noconfirm", false, "noconfirm")
+
+	overridePolicy = approveCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+	simulateOnly = approveCmd.Flags().Bool("simulate-only", false, "run eth_call at pending block and exit without sending")
 }
 
 func approveToken(cmd *cobra.Command, args []string) {
@@ -55,13 +62,13 @@ func approveToken(cmd *cobra.Command, args []string) {
 	utils.ExitWhen(logger, *spender == "", "need token spender")
 	utils.ExitWhen(logger, *amount == "", "need token amount")
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "query network error: %v", err)
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 
 	acc, err := database.QueryAccountOrCurrent(*account, *accountIndex)
@@ -81,9 +88,13 @@ func approveToken(cmd *cobra.Command, args []string) {
 	realAmount, err := utils.Erc20AmountFromHuman(*amount, decimalsStr)
 	utils.ExitWhenErr(logger, err, "convert amount error: %v", err)
 
-	hash, err := erc20.WriteErc20(ctx, *contract, *noconfirm, client, net, accountDetails, erc20.Erc20Approve, *spender, realAmount, "")
+	hash, err := erc20.WriteErc20(ctx, *contract, *noconfirm, *overridePolicy, *simulateOnly, client, net, accountDetails, erc20.Erc20Approve, *spender, realAmount, "")
 	utils.ExitWhenErr(logger, err, "approve token error: %v", err)
 
+	if *simulateOnly {
+		return
+	}
+
 	// fmt.Printf("tx hash: %s\n", hash)
 	logger.Info().Msgf("tx hash: %s", hash)
 
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"math/big"
 	cmd "met/cmd"
+	"met/consts"
 	database "met/database"
+	transaction "met/transaction"
 	types "met/types"
 	utils "met/utils"
 	"os"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/spf13/cobra"
@@ -148,8 +151,8 @@ const (
 	Erc20Approve
 )
 
-// 写erc20
-func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *ethclient.Client, net *database.Network, accountDetails *types.AccountDetails, funcType Erc20WritFuncType, arg1, arg2, arg3 string) (string, error) {
+// 写erc20，发送之前在pending区块上模拟调用，simulateOnly为true时模拟之后返回空的tx link
+func WriteErc20(ctx context.Context, contract string, noconfirm bool, overridePolicy bool, simulateOnly bool, client *ethclient.Client, net *database.Network, accountDetails *types.AccountDetails, funcType Erc20WritFuncType, arg1, arg2, arg3 string) (string, error) {
 	logger := utils.GetLogger("WriteErc20")
 	logger.Debug().Msgf("network info: %v", net)
 
@@ -176,16 +179,18 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 		return "", fmt.Errorf("create private key error: %w", err)
 	}
 
-	logger.Info().Msg("query chain id")
-	chainId, err := client.ChainID(ctx)
+	logger.Info().Msg("verify chain id")
+	chainId, err := transaction.VerifyChainId(ctx, client, net)
 	if err != nil {
-		return "", fmt.Errorf("get chain id error: %w", err)
+		return "", err
 	}
 
 	transactor, err := bind.NewKeyedTransactorWithChainID(privateKey, chainId)
 	if err != nil {
 		return "", err
 	}
+	var signedTx *ethTypes.Transaction
+	transactor.Signer = transaction.RecordingSigner(transaction.PolicySigner(accountDetails.Name, net.Name, overridePolicy, transactor.Signer), &signedTx)
 
 	contractAddress := common.HexToAddress(contract)
 	erc20Instance, err := utils.NewErc20(contractAddress, client)
@@ -215,6 +220,14 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 		// TODO
 		// logger.Info().Msgf("Amount readable: %v", arg2)
 
+		err = simulateErc20(client, net, addressStr, contract, consts.Erc20Transfer, to, amount)
+		if err != nil {
+			return "", err
+		}
+		if simulateOnly {
+			return "", nil
+		}
+
 		if !noconfirm {
 			input, err := utils.ReadChar("Send ? [y/N] ")
 			utils.ExitWhenErr(logger, err, "read input error: %s", err)
@@ -224,12 +237,18 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 			}
 
 		}
-		tx, err := erc20Instance.Transfer(transactor, to, amount)
+		tx, err := transaction.TransactWithNonce(ctx, client, net.Name, transactor, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return erc20Instance.Transfer(opts, to, amount)
+		})
+		if signedTx != nil {
+			transaction.RecordTxSignature(accountDetails.Name, accountDetails.CurrentIndex, net.Name, signedTx, transaction.OutcomeSent, err)
+		}
 		if err != nil {
 			return "", err
 		}
+		transaction.RecordTransaction(accountDetails.Name, accountDetails.CurrentIndex, net.Name, tx)
 
-		return fmt.Sprintf("%v/tx/%v", net.Explorer, tx.Hash().Hex()), nil
+		return net.TxLink(tx.Hash().Hex()), nil
 
 	case Erc20TransferFrom:
 		if arg1 == "" {
@@ -256,6 +275,14 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 		// TODO
 		// logger.Info().Msgf("Amount readable: %v", arg3)
 
+		err = simulateErc20(client, net, addressStr, contract, consts.Erc20TransferFrom, from, to, amount)
+		if err != nil {
+			return "", err
+		}
+		if simulateOnly {
+			return "", nil
+		}
+
 		if !noconfirm {
 			input, err := utils.ReadChar("Send ? [y/N] ")
 			utils.ExitWhenErr(logger, err, "read input error: %s", err)
@@ -266,12 +293,18 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 
 		}
 
-		tx, err := erc20Instance.TransferFrom(transactor, from, to, amount)
+		tx, err := transaction.TransactWithNonce(ctx, client, net.Name, transactor, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return erc20Instance.TransferFrom(opts, from, to, amount)
+		})
+		if signedTx != nil {
+			transaction.RecordTxSignature(accountDetails.Name, accountDetails.CurrentIndex, net.Name, signedTx, transaction.OutcomeSent, err)
+		}
 		if err != nil {
 			return "", err
 		}
+		transaction.RecordTransaction(accountDetails.Name, accountDetails.CurrentIndex, net.Name, tx)
 
-		return fmt.Sprintf("%v/tx/%v", net.Explorer, tx.Hash().Hex()), nil
+		return net.TxLink(tx.Hash().Hex()), nil
 
 	case Erc20Approve:
 		if arg1 == "" {
@@ -294,6 +327,14 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 		// TODO
 		// logger.Info().Msgf("Amount readable: %v", arg2)
 
+		err = simulateErc20(client, net, addressStr, contract, consts.Erc20Approve, spender, amount)
+		if err != nil {
+			return "", err
+		}
+		if simulateOnly {
+			return "", nil
+		}
+
 		if !noconfirm {
 			input, err := utils.ReadChar("Send ? [y/N] ")
 			utils.ExitWhenErr(logger, err, "read input error: %s", err)
@@ -304,15 +345,35 @@ func WriteErc20(ctx context.Context, contract string, noconfirm bool, client *et
 
 		}
 
-		tx, err := erc20Instance.Approve(transactor, spender, amount)
+		tx, err := transaction.TransactWithNonce(ctx, client, net.Name, transactor, func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return erc20Instance.Approve(opts, spender, amount)
+		})
+		if signedTx != nil {
+			transaction.RecordTxSignature(accountDetails.Name, accountDetails.CurrentIndex, net.Name, signedTx, transaction.OutcomeSent, err)
+		}
 		if err != nil {
 			return "", err
 		}
+		transaction.RecordTransaction(accountDetails.Name, accountDetails.CurrentIndex, net.Name, tx)
 
-		return fmt.Sprintf("%v/tx/%v", net.Explorer, tx.Hash().Hex()), nil
+		return net.TxLink(tx.Hash().Hex()), nil
 
 	default:
 		return "", errors.New("invalid erc20 write func type")
 
 	}
 }
+
+// simulateErc20 在pending区块上模拟erc20调用并打印结果
+func simulateErc20(client *ethclient.Client, net *database.Network, from string, contract string, method string, args ...any) error {
+	abiObj, err := transaction.ParseAbiJson(consts.Erc20Abi)
+	if err != nil {
+		return fmt.Errorf("parse erc20 abi error: %w", err)
+	}
+	data, err := abiObj.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("abi pack error: %w", err)
+	}
+	_, err = transaction.SimulateSend(client, net, from, contract, nil, data, consts.Erc20, nil)
+	return err
+}
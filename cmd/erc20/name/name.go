@@ -1,6 +1,7 @@
 package name
 
 import (
+	"context"
 	"met/cmd/erc20"
 	"met/database"
 	utils "met/utils"
@@ -34,13 +35,13 @@ func getName(cmd *cobra.Command, args []string) {
 
 	utils.ExitWhen(logger, *contract == "", "need contract address")
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "query network error: %v", err)
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 
 	tokenName, err := erc20.ReadErc20(ctx, *contract, client, net, erc20.Erc20Name, "", "")
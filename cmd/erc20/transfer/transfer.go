@@ -1,8 +1,9 @@
 package transfer
 
 import (
+	"context"
 	"crypto/ecdsa"
-	"fmt"
+	"math/big"
 	"met/cmd/erc20"
 	"met/consts"
 	database "met/database"
@@ -42,11 +43,14 @@ var (
 	gasLimitRatio *string
 
 	gasMode  *string
+	txType   *string
 	gasRatio *string
 	gasPrice *string
 	tipCap   *string
 	feeCap   *string
 
+	accessList *string
+
 	noconfirm *bool
 
 	confirmations *int8
@@ -57,6 +61,9 @@ var (
 
 	ledger           *bool
 	ledgerDerivePath *string
+
+	overridePolicy *bool
+	simulateOnly   *bool
 )
 
 func init() {
@@ -79,22 +86,28 @@ func init() {
 	gasLimit = transferCmd.Flags().String("gasLimit", "", "gas limit")
 	gasLimitRatio = transferCmd.Flags().String("gasLimitRatio", "", "gas limit ratio")
 
-	gasMode = transferCmd.Flags().String("gasMode", "auto", "gas mode(eg: auto,legacy,1559)")
-	gasRatio = transferCmd.Flags().String("gasRatio", "", "gasRatio")
+	gasMode = transferCmd.Flags().String("gasMode", "", "gas mode(eg: auto,legacy,1559), use network default or auto if empty")
+	txType = transferCmd.Flags().String("txType", "", "tx type(0: legacy, 1: access list, 2: eip1559), independent of gas mode, decided by gas mode if empty (legacy: 1, eip1559: 2), fall back to 0 when type 1 or 2 is rejected")
+	gasRatio = transferCmd.Flags().String("gasRatio", "", "gasRatio, use network default if empty")
 	gasPrice = transferCmd.Flags().String("gasPrice", "", "gas price(gwei)")
 	tipCap = transferCmd.Flags().String("tipCap", "", "tipCap(gwei)")
 	feeCap = transferCmd.Flags().String("feeCap", "", "feeCap(gwei)")
 
+	accessList = transferCmd.Flags().String("access-list", "", "access list of type-1 and type-2 tx: auto (generate with eth_createAccessList and attach only when it saves gas) or json file ([{\"address\":\"0x..\",\"storageKeys\":[\"0x..\"]}])")
+
 	noconfirm = transferCmd.Flags().Bool("noconfirm", false, "noconfirm")
 
-	confirmations = transferCmd.Flags().Int8("confirmations", 0, "blocks of confirmation (N < 0: send tx without receipt. 0: send tx with receipt. N > 0: send tx with receipt and N blocks confirmations)")
+	confirmations = transferCmd.Flags().Int8("confirmations", 0, "blocks of confirmation (N < 0: send tx without receipt. 0: send tx with receipt. N > 0: send tx with receipt and N blocks confirmations), use network default if not set")
 
 	blockHeight = transferCmd.Flags().String("height", "", "send tx after block height")
-	blockHeightInterval = transferCmd.Flags().Uint("heightInterval", 2, "check block height interval(unit: ms)")
-	blockHeightTimeout = transferCmd.Flags().Uint("heightTimeout", 600, "check block height timeout(unit: s)")
+	blockHeightInterval = transferCmd.Flags().Uint("heightInterval", 2, "check block height interval when rpc does not support subscriptions(unit: ms)")
+	blockHeightTimeout = transferCmd.Flags().Uint("heightTimeout", 0, "check block height timeout(unit: s), use network receipt timeout or 600 if 0")
 
 	ledger = transferCmd.Flags().Bool("ledger", false, "use ledger to sign tx, this flag will ignore --account and --account-index")
 	ledgerDerivePath = transferCmd.Flags().String("ledgerDerivePath", "m/44'/60'/0'/0/0", "ledger derive path, works only when --ledger is true")
+
+	overridePolicy = transferCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+	simulateOnly = transferCmd.Flags().Bool("simulate-only", false, "run eth_call at pending block and exit without sending")
 }
 
 func transferToken(cmd *cobra.Command, args []string) {
@@ -145,10 +158,10 @@ func transferToken(cmd *cobra.Command, args []string) {
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "load network error: %s", err)
 
-	ctx, cancel := utils.DefaultTimeoutContext()
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
 	defer cancel()
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 	defer client.Close()
 
@@ -157,30 +170,52 @@ func transferToken(cmd *cobra.Command, args []string) {
 	logger.Info().Msgf("Account Index: %v", accoutnIndex)
 	logger.Info().Msgf("Address: %s", from)
 	logger.Info().Msgf("Network Name: %s", net.Name)
-	logger.Info().Msgf("Network RPC: %s", net.Rpc)
+	logger.Info().Msgf("Network RPC: %s", utils.DisplayRpcUrl(net.Rpc))
 
-	input, err := transaction.ParseErc20Input(client, *contract, *symbol, *decimals, consts.Erc20Transfer, *receiver, *amount)
+	input, err := transaction.ParseErc20Input(ctx, client, *contract, *symbol, *decimals, consts.Erc20Transfer, *receiver, *amount)
 	utils.ExitWhenErr(logger, err, "%v", err)
 
-	mode := ttypes.GasMode(ttypes.GasMode_value[*gasMode])
+	mode, err := ttypes.ParseGasMode(*gasMode)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	typ, err := ttypes.ParseTxType(*txType)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	// 没有指定--confirmations时使用network的默认值
+	var txConfirmations *int8
+	if cmd.Flags().Changed("confirmations") {
+		txConfirmations = confirmations
+	}
 
 	// wait block height
-	err = transaction.WaitBlock(client, *blockHeight, *blockHeightInterval, *blockHeightTimeout)
+	err = transaction.WaitBlock(client, net, *blockHeight, *blockHeightInterval, *blockHeightTimeout)
 	utils.ExitWhenErr(logger, err, "WaitBlock error: %v", err)
 
+	// simulate
+	var simValue *big.Int
+	if *value != "" {
+		simValue, err = utils.ParseUnits(*value, utils.UnitEth)
+		utils.ExitWhenErr(logger, err, "parse value: %v error: %v", *value, err)
+	}
+	_, err = transaction.SimulateSend(client, net, from, *contract, simValue, input, consts.Erc20, nil)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	if *simulateOnly {
+		return
+	}
+
 	// build tx
-	tx, err := transaction.BuildTx(client, from, *contract, value, input, *ledger, mode, *nonce, *chainID, *gasLimit, *gasLimitRatio, *gasRatio, *gasPrice, *tipCap, *feeCap, false)
+	tx, err := transaction.BuildTx(client, net, from, *contract, value, input, *ledger, mode, typ, *nonce, *chainID, *gasLimit, *gasLimitRatio, *gasRatio, *gasPrice, *tipCap, *feeCap, false, *accessList, nil)
 	utils.ExitWhenErr(logger, err, "build tx error: %s", err)
 
 	// send tx
-	receipt, tx, err := transaction.SendTx(client, from, tx, *ledger, ledgerWallet, ledgerAccount, privateKey, net, *noconfirm, *confirmations)
+	receipt, tx, err := transaction.SendTx(client, from, accountName, accoutnIndex, tx, *nonce == "", *ledger, ledgerWallet, ledgerAccount, privateKey, net, *noconfirm, txConfirmations, *overridePolicy)
 	utils.ExitWhenErr(logger, err, "send transaction error: %v", err)
 
 	if receipt != nil {
 		utils.ShowReceipt(logger, receipt)
 	}
 
-	link := fmt.Sprintf("%v/tx/%v", net.Explorer, tx.Hash())
+	link := net.TxLink(tx.Hash().Hex())
 	logger.Info().Msgf("tx link: %v", link)
 
 }
@@ -1,6 +1,7 @@
 package transferFrom
 
 import (
+	"context"
 	"fmt"
 	"met/cmd/erc20"
 	"met/database"
@@ -29,6 +30,9 @@ var (
 	decimals *uint8
 
 	noconfirm *bool
+
+	overridePolicy *bool
+	simulateOnly   *bool
 )
 
 func init() {
@@ -45,6 +49,9 @@ func init() {
 	decimals = transferFromCmd.Flags().Uint8("decimals", 0, "token decimals(optional)")
 
 	noconfirm = transferFromCmd.Flags().Bool("noconfirm", false, "noconfirm")
+
+	overridePolicy = transferFromCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+	simulateOnly = transferFromCmd.Flags().Bool("simulate-only", false, "run eth_call at pending block and exit without sending")
 }
 
 func transferToken(cmd *cobra.Command, args []string) {
@@ -58,13 +65,13 @@ func transferToken(cmd *cobra.Command, args []string) {
 	utils.ExitWhen(logger, *to == "", "need to address")
 	utils.ExitWhen(logger, *amount == "", "need token amount")
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "query network error: %v", err)
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 
 	acc, err := database.QueryAccountOrCurrent(*account, *accountIndex)
@@ -84,9 +91,13 @@ func transferToken(cmd *cobra.Command, args []string) {
 	realAmount, err := utils.Erc20AmountFromHuman(*amount, decimalsStr)
 	utils.ExitWhenErr(logger, err, "convert amount error: %v", err)
 
-	hash, err := erc20.WriteErc20(ctx, *contract, *noconfirm, client, net, accountDetails, erc20.Erc20TransferFrom, *from, *to, realAmount)
+	hash, err := erc20.WriteErc20(ctx, *contract, *noconfirm, *overridePolicy, *simulateOnly, client, net, accountDetails, erc20.Erc20TransferFrom, *from, *to, realAmount)
 	utils.ExitWhenErr(logger, err, "transfer token error: %v", err)
 
+	if *simulateOnly {
+		return
+	}
+
 	// fmt.Printf("tx hash: %s\n", hash)
 	logger.Info().Msgf("tx hash: %s", hash)
 
@@ -3,6 +3,8 @@ package add
 import (
 	"met/cmd/network"
 	database "met/database"
+	"met/presets"
+	ttypes "met/types"
 	utils "met/utils"
 
 	"github.com/spf13/cobra"
@@ -17,18 +19,27 @@ var addCmd = &cobra.Command{
 
 var (
 	name     *string
-	rpc      *string
+	rpcs     *[]string
+	weights  *[]uint
 	symbol   *string
 	explorer *string
+	chainId  *uint64
+	preset   *string
+
+	transportFlags *network.TransportFlags
 )
 
 func init() {
 	network.NetworkCmd.AddCommand(addCmd)
 
 	name = addCmd.Flags().String("name", "", "network name")
-	rpc = addCmd.Flags().String("rpc", "", "network rpc")
+	rpcs = addCmd.Flags().StringArray("rpc", nil, "network rpc, repeat for multiple rpcs in failover order")
+	weights = addCmd.Flags().UintSlice("weights", nil, "weight of each rpc, eg: 3,1,0 (0 means backup only), default 1 for all")
 	symbol = addCmd.Flags().String("symbol", "", "native token symbo,eg: ETH BNB")
 	explorer = addCmd.Flags().String("explorer", "", "network explorer")
+	chainId = addCmd.Flags().Uint64("chainId", 0, "expected chain id, abort if rpc returns a different one")
+	transportFlags = network.AddTransportFlags(addCmd.Flags())
+	preset = addCmd.Flags().String("preset", "", "add from built-in preset (see: network presets list), other flags override the preset")
 }
 
 func addNetwork(cmd *cobra.Command, args []string) {
@@ -37,17 +48,59 @@ func addNetwork(cmd *cobra.Command, args []string) {
 		logger = utils.GetLogger("addNetwork")
 	)
 
+	var defaults database.NetworkDefaults
+	if *preset != "" {
+		p, err := presets.Find(*preset)
+		utils.ExitWhenErr(logger, err, "%s", err)
+
+		if *name == "" {
+			*name = p.Name
+		}
+		if len(*rpcs) == 0 {
+			*rpcs = p.Rpcs
+		}
+		if *symbol == "" {
+			*symbol = p.Symbol
+		}
+		if *explorer == "" {
+			*explorer = p.Explorer
+		}
+		if *chainId == 0 {
+			*chainId = p.ChainId
+		}
+		// 不支持eip1559的链默认使用legacy gas mode
+		if !p.Eip1559 {
+			defaults.GasMode = ttypes.GasMode_name[int32(ttypes.GasModeLegacy)]
+		}
+	}
+
 	utils.ExitWhen(logger, *name == "", "need name")
-	utils.ExitWhen(logger, *rpc == "", "need rpc")
 	utils.ExitWhen(logger, *symbol == "", "need symbol")
 
-	network := database.Network{
-		Name:     *name,
-		Rpc:      *rpc,
-		Symbol:   *symbol,
-		Explorer: *explorer,
-		Current:  false,
+	networkRpcs, err := network.ParseRpcs(*rpcs, *weights)
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	transport, err := transportFlags.Options()
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	rpcChainId, err := network.QueryRpcsChainId(ctx, networkRpcs, transport)
+	utils.ExitWhenErr(logger, err, "%s", err)
+	utils.ExitWhen(logger, *chainId != 0 && rpcChainId != *chainId, "chain id mismatch, expected: %v rpc: %v", *chainId, rpcChainId)
+
+	net := database.Network{
+		Name:      *name,
+		Rpc:       networkRpcs[0].Url,
+		Rpcs:      networkRpcs,
+		Transport: transport,
+		Symbol:    *symbol,
+		Explorer:  *explorer,
+		ChainId:   rpcChainId,
+		Defaults:  defaults,
+		Current:   false,
 	}
-	err = database.AddNetwork(&network)
+	err = database.AddNetwork(&net)
 	utils.ExitWhenErr(logger, err, "Add netowrk error: %s", err)
 }
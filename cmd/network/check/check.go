@@ -0,0 +1,153 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"met/cmd/network"
+	"met/config"
+	database "met/database"
+	utils "met/utils"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "check health of network rpcs",
+	Long:  "check latency, head lag, chain id, client version and supported features of each rpc",
+	Run:   checkNetwork,
+}
+
+var (
+	name    *string
+	all     *bool
+	samples *int
+	format  *string
+)
+
+func init() {
+	network.NetworkCmd.AddCommand(checkCmd)
+
+	name = checkCmd.Flags().String("name", "", "network name, default current network")
+	all = checkCmd.Flags().Bool("all", false, "check all networks")
+	samples = checkCmd.Flags().Int("samples", 10, "number of eth_blockNumber requests to measure latency")
+	format = checkCmd.Flags().String("format", "table", "output format: table json")
+}
+
+// checkTimeout 检查一个network的所有rpc的超时时间
+const checkTimeout = time.Minute
+
+type networkCheckResult struct {
+	Network string                 `json:"network"`
+	ChainId uint64                 `json:"chainId"`
+	Rpcs    []utils.RpcCheckResult `json:"rpcs"`
+}
+
+func checkNetwork(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("checkNetwork")
+
+	*format = config.Format(cmd.Flags(), *format, "table", "json")
+	utils.ExitWhen(logger, *format != "table" && *format != "json", "invalid format: %v", *format)
+	utils.ExitWhen(logger, *samples <= 0, "samples must be positive")
+	utils.ExitWhen(logger, *all && *name != "", "--all conflicts with --name")
+
+	var networks []database.Network
+	if *all {
+		var err error
+		networks, err = database.QueryAllNetworks()
+		utils.ExitWhenErr(logger, err, "query networks error: %s", err)
+	} else {
+		net, err := database.QueryNetworkOrCurrent(*name)
+		utils.ExitWhenErr(logger, err, "query network: %v error: %s", *name, err)
+		networks = append(networks, *net)
+	}
+
+	var results []networkCheckResult
+	for _, net := range networks {
+		logger.Info().Msgf("check network: %v", net.Name)
+		results = append(results, checkRpcs(net))
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(results)
+		utils.ExitWhenErr(logger, err, "encode json error: %s", err)
+	case "table":
+		printTable(results)
+	}
+}
+
+// checkRpcs 并行检查network的所有rpc，并计算每个rpc落后最高区块的数量
+// 每个network单独计算超时，--all时前面较慢的network不会影响后面的network
+func checkRpcs(net database.Network) networkCheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	endpoints := net.Endpoints()
+	rpcs := make([]utils.RpcCheckResult, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rpcs[i] = utils.CheckRpc(ctx, endpoints[i].Url, *samples, net.Transport)
+		}(i)
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, r := range rpcs {
+		if r.Error == "" {
+			highest = max(highest, r.Head)
+		}
+	}
+	for i := range rpcs {
+		if rpcs[i].Error == "" {
+			rpcs[i].Lag = highest - rpcs[i].Head
+			if net.ChainId != 0 && rpcs[i].ChainId != net.ChainId {
+				rpcs[i].Error = fmt.Sprintf("chain id mismatch, network: %v", net.ChainId)
+			}
+		}
+	}
+
+	return networkCheckResult{Network: net.Name, ChainId: net.ChainId, Rpcs: rpcs}
+}
+
+func printTable(results []networkCheckResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NETWORK\tRPC\tP50\tP90\tP99\tHEAD\tLAG\tCHAIN ID\tCLIENT\t1559\tFEE HISTORY\tDEBUG\tTRACE\tARCHIVE\tSUBSCRIPTIONS\tERROR")
+	for _, result := range results {
+		for _, r := range result.Rpcs {
+			if r.Error != "" && r.Head == 0 {
+				fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t%s\n", result.Network, r.Url, r.Error)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				result.Network, r.Url,
+				ms(r.LatencyP50), ms(r.LatencyP90), ms(r.LatencyP99),
+				r.Head, r.Lag, r.ChainId, r.ClientVersion,
+				yesNo(r.Eip1559), yesNo(r.FeeHistory), yesNo(r.Debug), yesNo(r.Trace), yesNo(r.Archive), yesNo(r.Subscriptions),
+				r.Error)
+		}
+	}
+	w.Flush()
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
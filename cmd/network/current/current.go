@@ -21,9 +21,10 @@ func init() {
 
 func showCurrentNetwork(cmd *cobra.Command, args []string) {
 	logger := utils.GetLogger("showCurrentNetwork")
-	current, err := database.CurrentNetwork()
+	// profile或者MET_NETWORK设置了network时显示它
+	current, err := database.QueryNetworkOrCurrent("")
 	utils.ExitWhenErr(logger, err, "show current entwork error: %s", err)
 
-	network.ShowNetwork(current)
+	network.ShowNetwork(*current)
 
 }
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"met/cmd/network"
+	database "met/database"
+	"met/presets"
+	utils "met/utils"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export networks to chainlist json",
+	Long:  "export networks to chainlist/ethereum-lists chains json, which can be imported by: network import",
+	Run:   exportNetworks,
+}
+
+var (
+	chainIds *[]uint
+	names    *[]string
+	output   *string
+)
+
+func init() {
+	network.NetworkCmd.AddCommand(exportCmd)
+
+	chainIds = exportCmd.Flags().UintSlice("chainId", nil, "only export these chain ids, eg: 1,10,137")
+	names = exportCmd.Flags().StringSlice("name", nil, "only export these networks, eg: eth,polygon")
+	output = exportCmd.Flags().String("output", "", "output file, stdout if empty")
+}
+
+func exportNetworks(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("exportNetworks")
+
+	networks, err := database.QueryAllNetworks()
+	utils.ExitWhenErr(logger, err, "query networks error: %s", err)
+
+	entries := []presets.ChainlistEntry{}
+	for _, net := range networks {
+		if len(*chainIds) > 0 && !slices.Contains(*chainIds, uint(net.ChainId)) {
+			continue
+		}
+		if len(*names) > 0 && !slices.Contains(*names, net.Name) {
+			continue
+		}
+		entry, skipped := presets.FromNetwork(net)
+		for _, url := range skipped {
+			logger.Warn().Msgf("network: %v skip rpc: %v, it may contain a secret, use ${NAME} or {NAME} reference to export it", net.Name, url)
+		}
+		entries = append(entries, entry)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		utils.ExitWhenErr(logger, err, "create file: %v error: %s", *output, err)
+		defer f.Close()
+		w = f
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(entries)
+	utils.ExitWhenErr(logger, err, "export networks error: %s", err)
+
+	if *output != "" {
+		logger.Info().Msgf("%d networks exported to %v", len(entries), *output)
+	}
+}
@@ -0,0 +1,66 @@
+package importNetwork
+
+import (
+	"met/cmd/network"
+	database "met/database"
+	"met/presets"
+	utils "met/utils"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import networks from chainlist json",
+	Long:  "import networks from chainlist/ethereum-lists chains json, network name is the shortName, existing networks are skipped",
+	Run:   importNetworks,
+}
+
+var (
+	file     *string
+	chainIds *[]uint
+)
+
+func init() {
+	network.NetworkCmd.AddCommand(importCmd)
+
+	file = importCmd.Flags().String("file", "", "chainlist json file")
+	chainIds = importCmd.Flags().UintSlice("chainId", nil, "only import these chain ids, eg: 1,10,137")
+}
+
+func importNetworks(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("importNetworks")
+
+	utils.ExitWhen(logger, *file == "", "need file")
+
+	data, err := os.ReadFile(*file)
+	utils.ExitWhenErr(logger, err, "read file: %v error: %s", *file, err)
+
+	entries, err := presets.ParseChainlist(data)
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	var networks []database.Network
+	for _, entry := range entries {
+		if len(*chainIds) > 0 && !slices.Contains(*chainIds, uint(entry.ChainId)) {
+			continue
+		}
+
+		net, err := entry.ToNetwork()
+		if err != nil {
+			logger.Warn().Msgf("skip chain: %v", err)
+			continue
+		}
+		networks = append(networks, net)
+	}
+	utils.ExitWhen(logger, len(networks) == 0, "no network to import")
+
+	skipped, err := database.ImportNetworks(networks)
+	utils.ExitWhenErr(logger, err, "import networks error: %s", err)
+
+	for _, name := range skipped {
+		logger.Warn().Msgf("network: %v already exists, skipped", name)
+	}
+	logger.Info().Msgf("%d networks imported", len(networks)-len(skipped))
+}
@@ -4,11 +4,15 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package network
 
 import (
+	"context"
 	"fmt"
 	cmd "met/cmd"
 	database "met/database"
+	utils "met/utils"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // NetworkCmd represents the network command
@@ -39,9 +43,139 @@ func init() {
 
 func ShowNetwork(network database.Network) {
 	fmt.Printf("Network %s\n", network.Name)
-	fmt.Printf("Rpc: %s\n", network.Rpc)
+	if len(network.Rpcs) > 1 {
+		for i, rpc := range network.Rpcs {
+			fmt.Printf("Rpc %d: %s (weight: %d)\n", i, utils.DisplayRpcUrl(rpc.Url), rpc.Weight)
+		}
+	} else {
+		fmt.Printf("Rpc: %s\n", utils.DisplayRpcUrl(network.Rpc))
+	}
+	for _, line := range network.Transport.Summary() {
+		fmt.Println(line)
+	}
+	if network.ChainId != 0 {
+		fmt.Printf("ChainId: %d\n", network.ChainId)
+	} else {
+		fmt.Printf("ChainId: unknown\n")
+	}
 	fmt.Printf("Symbol: %s\n", network.Symbol)
 	fmt.Printf("Explorer: %s\n", network.Explorer)
+	for _, line := range network.Defaults.Summary() {
+		fmt.Println(line)
+	}
 	fmt.Printf("Current: %v\n", network.Current)
 	fmt.Println()
 }
+
+// ParseRpcs weights为空时每个rpc的权重都是1
+func ParseRpcs(urls []string, weights []uint) ([]database.NetworkRpc, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("need rpc")
+	}
+	if len(weights) != 0 && len(weights) != len(urls) {
+		return nil, fmt.Errorf("%d weights for %d rpcs", len(weights), len(urls))
+	}
+
+	var rpcs []database.NetworkRpc
+	for i, url := range urls {
+		rpc := database.NetworkRpc{Url: url, Weight: 1}
+		if len(weights) != 0 {
+			rpc.Weight = weights[i]
+		}
+		rpcs = append(rpcs, rpc)
+	}
+	return rpcs, nil
+}
+
+// QueryRpcsChainId 分别查询每个rpc的chainId，不一致时返回错误，缺少占位符环境变量的rpc跳过
+func QueryRpcsChainId(ctx context.Context, rpcs []database.NetworkRpc, options utils.TransportOptions) (uint64, error) {
+	logger := utils.GetLogger("QueryRpcsChainId")
+
+	var chainId uint64
+	for _, rpc := range rpcs {
+		if _, err := utils.ExpandSecret(rpc.Url); err != nil {
+			logger.Warn().Msgf("skip checking chain id: %v", err)
+			continue
+		}
+
+		client, err := utils.DialRpcs(ctx, []utils.RpcEndpoint{{Url: rpc.Url, Weight: 1}}, options)
+		if err != nil {
+			return 0, fmt.Errorf("dial rpc: %v error: %w", utils.DisplayRpcUrl(rpc.Url), err)
+		}
+
+		id, err := client.ChainID(ctx)
+		client.Close()
+		if err != nil {
+			return 0, fmt.Errorf("query chain id of rpc: %v error: %w", utils.DisplayRpcUrl(rpc.Url), err)
+		}
+		if !id.IsUint64() {
+			return 0, fmt.Errorf("chain id: %v too large", id)
+		}
+		logger.Info().Msgf("rpc: %v chain id: %v", utils.DisplayRpcUrl(rpc.Url), id)
+
+		if chainId != 0 && id.Uint64() != chainId {
+			return 0, fmt.Errorf("rpc: %v chain id: %v differs from other rpcs: %v", utils.DisplayRpcUrl(rpc.Url), id, chainId)
+		}
+		chainId = id.Uint64()
+	}
+
+	if chainId == 0 {
+		return 0, fmt.Errorf("no rpc available to query chain id")
+	}
+	return chainId, nil
+}
+
+// TransportFlags network add和set-transport共用的传输设置参数
+type TransportFlags struct {
+	headers     *[]string
+	bearerToken *string
+	basicAuth   *string
+	clientCert  *string
+	clientKey   *string
+	caCert      *string
+	proxy       *string
+}
+
+func AddTransportFlags(flags *pflag.FlagSet) *TransportFlags {
+	return &TransportFlags{
+		headers:     flags.StringArray("header", nil, "custom http header, eg: --header 'X-Api-Key: ${API_KEY}'"),
+		bearerToken: flags.String("bearer-token", "", "bearer token, eg: ${TOKEN} or file:~/.secrets/token"),
+		basicAuth:   flags.String("basic-auth", "", "basic auth user:password, eg: user:${PASSWORD}"),
+		clientCert:  flags.String("client-cert", "", "mTLS client certificate PEM file"),
+		clientKey:   flags.String("client-key", "", "mTLS client key PEM file"),
+		caCert:      flags.String("ca-cert", "", "custom CA certificate PEM file"),
+		proxy:       flags.String("proxy", "", "proxy url: http:// https:// socks5://"),
+	}
+}
+
+func (f *TransportFlags) Options() (utils.TransportOptions, error) {
+	options := utils.TransportOptions{
+		BearerToken: *f.bearerToken,
+		BasicAuth:   *f.basicAuth,
+		ClientCert:  *f.clientCert,
+		ClientKey:   *f.clientKey,
+		CaCert:      *f.caCert,
+		Proxy:       *f.proxy,
+	}
+	if options.BearerToken != "" && options.BasicAuth != "" {
+		return options, fmt.Errorf("--bearer-token conflicts with --basic-auth")
+	}
+
+	for _, header := range *f.headers {
+		k, v, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(k) == "" {
+			return options, fmt.Errorf("invalid header: %v, format: 'Key: Value'", header)
+		}
+		if options.Headers == nil {
+			options.Headers = make(map[string]string)
+		}
+		options.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	// 提前检查证书和代理
+	if _, err := options.HttpTransport(); err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
@@ -0,0 +1,54 @@
+package list
+
+import (
+	"fmt"
+	"met/cmd/network/presets"
+	presetsCatalog "met/presets"
+	utils "met/utils"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"show"},
+	Short:   "list network presets",
+	Long:    "list network presets, {NAME} placeholders in rpc are filled from env var NAME when dialing",
+	Run:     listPresets,
+}
+
+var (
+	testnet *bool
+)
+
+func init() {
+	presets.PresetsCmd.AddCommand(listCmd)
+
+	testnet = listCmd.Flags().Bool("testnet", false, "only show testnets")
+}
+
+func listPresets(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("listPresets")
+
+	catalog, err := presetsCatalog.Load()
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	fmt.Printf("Network presets version: %d\n\n", catalog.Version)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCHAIN ID\tSYMBOL\t1559\tEXPLORER\tRPCS")
+	for _, preset := range catalog.Networks {
+		if *testnet && !preset.Testnet {
+			continue
+		}
+		eip1559 := "no"
+		if preset.Eip1559 {
+			eip1559 = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", preset.Name, preset.ChainId, preset.Symbol, eip1559, preset.Explorer, strings.Join(preset.Rpcs, " "))
+	}
+	w.Flush()
+}
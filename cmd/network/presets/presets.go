@@ -0,0 +1,17 @@
+package presets
+
+import (
+	"met/cmd/network"
+
+	"github.com/spf13/cobra"
+)
+
+var PresetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "built-in network presets",
+	Long:  "built-in network presets, add one with: network add --preset <name>",
+}
+
+func init() {
+	network.NetworkCmd.AddCommand(PresetsCmd)
+}
@@ -0,0 +1,103 @@
+package setDefaults
+
+import (
+	"met/cmd/network"
+	database "met/database"
+	ttypes "met/types"
+	utils "met/utils"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var setDefaultsCmd = &cobra.Command{
+	Use:   "set-defaults",
+	Short: "set default gas mode, caps, confirmations, timeouts and explorer url of network",
+	Long:  "set defaults used by tx send and erc20 transfer when the flags are not given, only the given options are changed, use --reset to clear all",
+	Run:   setDefaults,
+}
+
+var (
+	name  *string
+	reset *bool
+
+	gasMode        *string
+	gasRatio       *string
+	maxFee         *string
+	confirmations  *int8
+	requestTimeout *uint
+	receiptTimeout *uint
+	explorerUrl    *string
+)
+
+func init() {
+	network.NetworkCmd.AddCommand(setDefaultsCmd)
+
+	name = setDefaultsCmd.Flags().String("name", "", "network name, default current network")
+	reset = setDefaultsCmd.Flags().Bool("reset", false, "clear all defaults before applying the given options")
+
+	gasMode = setDefaultsCmd.Flags().String("gasMode", "", "default gas mode(eg: auto,legacy,1559), empty to clear")
+	gasRatio = setDefaultsCmd.Flags().String("gasRatio", "", "default gasRatio, empty to clear")
+	maxFee = setDefaultsCmd.Flags().String("maxFee", "", "max gasPrice and gasFeeCap(gwei), empty to clear")
+	confirmations = setDefaultsCmd.Flags().Int8("confirmations", 0, "default blocks of confirmation")
+	requestTimeout = setDefaultsCmd.Flags().Uint("requestTimeout", 0, "rpc request timeout(unit: s), 0 to clear")
+	receiptTimeout = setDefaultsCmd.Flags().Uint("receiptTimeout", 0, "receipt and block height waiting timeout(unit: s), 0 to clear")
+	explorerUrl = setDefaultsCmd.Flags().String("explorerUrl", "", "explorer link template, eg: {explorer}/{type}/{id} or https://tronscan.org/#/{type}/{id}, {type} is tx or address, empty to clear")
+}
+
+func setDefaults(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("setDefaults")
+
+	net, err := database.QueryNetworkOrCurrent(*name)
+	utils.ExitWhenErr(logger, err, "query network: %v error: %s", *name, err)
+
+	defaults := net.Defaults
+	if *reset {
+		defaults = database.NetworkDefaults{}
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("gasMode") {
+		mode, err := ttypes.ParseGasMode(*gasMode)
+		utils.ExitWhenErr(logger, err, "%s", err)
+		defaults.GasMode = ""
+		if mode != 0 {
+			defaults.GasMode = ttypes.GasMode_name[int32(mode)]
+		}
+	}
+	if flags.Changed("gasRatio") {
+		if *gasRatio != "" {
+			ratio, err := decimal.NewFromString(*gasRatio)
+			utils.ExitWhenErr(logger, err, "parse gasRatio: %v error: %s", *gasRatio, err)
+			utils.ExitWhen(logger, !ratio.IsPositive(), "gasRatio must be positive")
+		}
+		defaults.GasRatio = *gasRatio
+	}
+	if flags.Changed("maxFee") {
+		if *maxFee != "" {
+			_, err := utils.ParseUnits(*maxFee, utils.UnitGwei)
+			utils.ExitWhenErr(logger, err, "parse maxFee: %v error: %s", *maxFee, err)
+		}
+		defaults.MaxFee = *maxFee
+	}
+	if flags.Changed("confirmations") {
+		defaults.Confirmations = confirmations
+	}
+	if flags.Changed("requestTimeout") {
+		defaults.RequestTimeout = *requestTimeout
+	}
+	if flags.Changed("receiptTimeout") {
+		defaults.ReceiptTimeout = *receiptTimeout
+	}
+	if flags.Changed("explorerUrl") {
+		utils.ExitWhen(logger, *explorerUrl != "" && !strings.Contains(*explorerUrl, database.ExplorerIdPlaceholder), "explorerUrl must contain %v", database.ExplorerIdPlaceholder)
+		defaults.ExplorerUrl = *explorerUrl
+	}
+
+	err = database.UpdateNetworkDefaults(net.Name, defaults)
+	utils.ExitWhenErr(logger, err, "update defaults error: %s", err)
+
+	net.Defaults = defaults
+	network.ShowNetwork(*net)
+}
@@ -0,0 +1,52 @@
+package setRpcs
+
+import (
+	"met/cmd/network"
+	database "met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var setRpcsCmd = &cobra.Command{
+	Use:   "set-rpcs",
+	Short: "replace rpcs of network",
+	Long:  "replace rpcs of network, all rpcs must return the chain id of the network",
+	Run:   setRpcs,
+}
+
+var (
+	name    *string
+	rpcs    *[]string
+	weights *[]uint
+)
+
+func init() {
+	network.NetworkCmd.AddCommand(setRpcsCmd)
+
+	name = setRpcsCmd.Flags().String("name", "", "network name")
+	rpcs = setRpcsCmd.Flags().StringArray("rpc", nil, "network rpc, repeat for multiple rpcs in failover order")
+	weights = setRpcsCmd.Flags().UintSlice("weights", nil, "weight of each rpc, eg: 3,1,0 (0 means backup only), default 1 for all")
+}
+
+func setRpcs(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("setRpcs")
+
+	utils.ExitWhen(logger, *name == "", "need name")
+
+	net, err := database.QueryNetwork(*name)
+	utils.ExitWhenErr(logger, err, "query network: %s error: %s", *name, err)
+
+	networkRpcs, err := network.ParseRpcs(*rpcs, *weights)
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	chainId, err := network.QueryRpcsChainId(ctx, networkRpcs, net.Transport)
+	utils.ExitWhenErr(logger, err, "%s", err)
+	utils.ExitWhen(logger, net.ChainId != 0 && chainId != net.ChainId, "chain id mismatch, network: %v rpc: %v", net.ChainId, chainId)
+
+	err = database.UpdateNetworkRpcs(*name, networkRpcs)
+	utils.ExitWhenErr(logger, err, "update rpcs error: %s", err)
+}
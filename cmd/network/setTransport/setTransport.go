@@ -0,0 +1,54 @@
+package setTransport
+
+import (
+	"met/cmd/network"
+	database "met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var setTransportCmd = &cobra.Command{
+	Use:   "set-transport",
+	Short: "replace http headers, auth, TLS and proxy settings of network",
+	Long:  "replace http headers, auth, TLS and proxy settings of network, run without options to clear them",
+	Run:   setTransport,
+}
+
+var (
+	name           *string
+	transportFlags *network.TransportFlags
+)
+
+func init() {
+	network.NetworkCmd.AddCommand(setTransportCmd)
+
+	name = setTransportCmd.Flags().String("name", "", "network name")
+	transportFlags = network.AddTransportFlags(setTransportCmd.Flags())
+}
+
+func setTransport(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("setTransport")
+
+	utils.ExitWhen(logger, *name == "", "need name")
+
+	net, err := database.QueryNetwork(*name)
+	utils.ExitWhenErr(logger, err, "query network: %s error: %s", *name, err)
+
+	transport, err := transportFlags.Options()
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	// 用新的设置检查rpc
+	rpcs := net.Rpcs
+	if len(rpcs) == 0 {
+		rpcs = []database.NetworkRpc{{Url: net.Rpc, Weight: 1}}
+	}
+	_, err = network.QueryRpcsChainId(ctx, rpcs, transport)
+	utils.ExitWhenErr(logger, err, "%s", err)
+
+	err = database.UpdateNetworkTransport(*name, transport)
+	utils.ExitWhenErr(logger, err, "update transport error: %s", err)
+}
@@ -0,0 +1,134 @@
+package fill
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"met/cmd/nonce"
+	database "met/database"
+	transaction "met/transaction"
+	ttypes "met/types"
+	utils "met/utils"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+var fillCmd = &cobra.Command{
+	Use:   "fill",
+	Short: "fill nonce gaps",
+	Long:  "send a 0 value transfer to self for each nonce gap, so that later txs can be mined",
+	Run:   fillNonce,
+}
+
+var (
+	accountName  *string
+	accountIndex *uint
+	networkName  *string
+
+	gasMode *string
+
+	noconfirm *bool
+
+	ledger           *bool
+	ledgerDerivePath *string
+
+	overridePolicy *bool
+)
+
+func init() {
+	nonce.NonceCmd.AddCommand(fillCmd)
+
+	accountName = fillCmd.Flags().String("account", "", "account name, use current if empty")
+	accountIndex = fillCmd.Flags().Uint("account-index", 0, "account index")
+	networkName = fillCmd.Flags().String("network", "", "network name, use current if empty")
+
+	gasMode = fillCmd.Flags().String("gasMode", "", "gas mode(eg: auto,legacy,1559), use network default or auto if empty")
+
+	noconfirm = fillCmd.Flags().BoolP("noconfirm", "y", false, "do not need to confirm")
+
+	ledger = fillCmd.Flags().Bool("ledger", false, "use ledger to sign tx, this flag will ignore --account and --account-index")
+	ledgerDerivePath = fillCmd.Flags().String("ledgerDerivePath", "m/44'/60'/0'/0/0", "ledger derive path, works only when --ledger is true")
+
+	overridePolicy = fillCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+}
+
+func fillNonce(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("fillNonce")
+
+	var (
+		err        error
+		privateKey *ecdsa.PrivateKey
+		from       string
+		name       string
+		index      uint
+
+		ledgerWallet  accounts.Wallet
+		ledgerAccount *accounts.Account
+	)
+
+	if *ledger {
+		ledgerWallet, ledgerAccount, err = utils.ConnectLedger(*ledgerDerivePath)
+		utils.ExitWhenErr(logger, err, "connect ledger error: %s", err)
+		defer ledgerWallet.Close()
+
+		name = "ledger"
+		from = ledgerAccount.Address.Hex()
+	} else {
+		account, err := database.QueryAccountOrCurrent(*accountName, *accountIndex)
+		utils.ExitWhenErr(logger, err, "load account error: %s", err)
+
+		details, err := ttypes.AccountToDetails(account)
+		utils.ExitWhenErr(logger, err, "calculate address error: %s", err)
+
+		privateKeyStr, err := details.PrivateKey()
+		utils.ExitWhenErr(logger, err, "get account private key error: %s", err)
+
+		privateKey, err = crypto.HexToECDSA(strings.TrimPrefix(privateKeyStr, "0x"))
+		utils.ExitWhenErr(logger, err, "parse privateKey error: %s", err)
+
+		from, err = details.Address()
+		utils.ExitWhenErr(logger, err, "get account address error: %s", err)
+
+		name = details.Name
+		index = details.CurrentIndex
+	}
+
+	mode, err := ttypes.ParseGasMode(*gasMode)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	net, err := database.QueryNetworkOrCurrent(*networkName)
+	utils.ExitWhenErr(logger, err, "query network: %v error: %v", *networkName, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+	defer client.Close()
+
+	status, err := transaction.GetNonceStatus(ctx, client, net.Name, common.HexToAddress(from))
+	utils.ExitWhenErr(logger, err, "get nonce status error: %v", err)
+
+	if len(status.Gaps) == 0 {
+		logger.Info().Msgf("no nonce gap, pending nonce: %v next nonce: %v", status.Pending, status.Next)
+		return
+	}
+	logger.Info().Msgf("nonce gaps: %v", status.Gaps)
+
+	// 填补空缺的交易不等待回执，空缺填补后之后的交易才能上链
+	noWait := int8(-1)
+	for _, gap := range status.Gaps {
+		value := "0"
+		tx, err := transaction.BuildTx(client, net, from, from, &value, nil, *ledger, mode, ttypes.TxTypeUnspecified, strconv.FormatUint(gap, 10), "", "", "", "", "", "", "", false, "", nil)
+		utils.ExitWhenErr(logger, err, "build tx for nonce: %v error: %s", gap, err)
+
+		_, tx, err = transaction.SendTx(client, from, name, index, tx, false, *ledger, ledgerWallet, ledgerAccount, privateKey, net, *noconfirm, &noWait, *overridePolicy)
+		utils.ExitWhenErr(logger, err, "send tx for nonce: %v error: %v", gap, err)
+
+		logger.Info().Msgf("filled nonce: %v tx: %v", gap, net.TxLink(tx.Hash().Hex()))
+	}
+}
@@ -0,0 +1,48 @@
+package nonce
+
+import (
+	"fmt"
+	cmd "met/cmd"
+	database "met/database"
+	"met/types"
+	utils "met/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+// NonceCmd 数据库中按(network, address)管理的nonce
+var NonceCmd = &cobra.Command{
+	Use:   "nonce",
+	Short: "nonce manager",
+	Long:  `nonces allocated by met are tracked per network and address in the database, so concurrent met processes do not reuse a nonce`,
+	Run:   nil,
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(NonceCmd)
+}
+
+// ResolveAddress address不为空时直接使用，否则使用账户的地址
+func ResolveAddress(accountName string, accountIndex uint, address string) (common.Address, error) {
+	if address != "" {
+		if !utils.IsValidAddress(address) {
+			return common.Address{}, fmt.Errorf("invalid address: %v", address)
+		}
+		return common.HexToAddress(address), nil
+	}
+
+	account, err := database.QueryAccountOrCurrent(accountName, accountIndex)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("query account: %v error: %w", accountName, err)
+	}
+	details, err := types.AccountToDetails(account)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("get account details error: %w", err)
+	}
+	addressStr, err := details.Address()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("get account address error: %w", err)
+	}
+	return common.HexToAddress(addressStr), nil
+}
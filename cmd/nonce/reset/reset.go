@@ -0,0 +1,62 @@
+package reset
+
+import (
+	"context"
+	"met/cmd/nonce"
+	database "met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "reset the next nonce of an address",
+	Long:  "set the next nonce allocated by met, default the pending nonce on chain, allocations from it are removed",
+	Run:   resetNonce,
+}
+
+var (
+	accountName  *string
+	accountIndex *uint
+	address      *string
+	networkName  *string
+	next         *uint64
+)
+
+func init() {
+	nonce.NonceCmd.AddCommand(resetCmd)
+
+	accountName = resetCmd.Flags().String("account", "", "account name, use current if empty")
+	accountIndex = resetCmd.Flags().Uint("account-index", 0, "account index")
+	address = resetCmd.Flags().String("address", "", "address, eg: a ledger address (conflicts with --account)")
+	networkName = resetCmd.Flags().String("network", "", "network name, use current if empty")
+	next = resetCmd.Flags().Uint64("nonce", 0, "next nonce, use pending nonce on chain if not set")
+}
+
+func resetNonce(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("resetNonce")
+
+	utils.ExitWhen(logger, *address != "" && *accountName != "", "--address conflicts with --account")
+	from, err := nonce.ResolveAddress(*accountName, *accountIndex, *address)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	net, err := database.QueryNetworkOrCurrent(*networkName)
+	utils.ExitWhenErr(logger, err, "query network: %v error: %v", *networkName, err)
+
+	if !cmd.Flags().Changed("nonce") {
+		ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+		defer cancel()
+
+		client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+		utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+		defer client.Close()
+
+		*next, err = client.PendingNonceAt(ctx, from)
+		utils.ExitWhenErr(logger, err, "query pending nonce error: %v", err)
+	}
+
+	err = database.ResetNonce(net.Name, from.Hex(), *next)
+	utils.ExitWhenErr(logger, err, "reset nonce error: %v", err)
+	logger.Info().Msgf("next nonce of address: %v on network: %v reset to: %v", from.Hex(), net.Name, *next)
+}
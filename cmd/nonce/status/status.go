@@ -0,0 +1,82 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"met/cmd/nonce"
+	database "met/database"
+	transaction "met/transaction"
+	utils "met/utils"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show nonces of an address",
+	Long:  "compare chain nonces with the nonces allocated in the database and list gaps",
+	Run:   showStatus,
+}
+
+var (
+	accountName  *string
+	accountIndex *uint
+	address      *string
+	networkName  *string
+)
+
+func init() {
+	nonce.NonceCmd.AddCommand(statusCmd)
+
+	accountName = statusCmd.Flags().String("account", "", "account name, use current if empty")
+	accountIndex = statusCmd.Flags().Uint("account-index", 0, "account index")
+	address = statusCmd.Flags().String("address", "", "address, eg: a ledger address (conflicts with --account)")
+	networkName = statusCmd.Flags().String("network", "", "network name, use current if empty")
+}
+
+func showStatus(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("showStatus")
+
+	utils.ExitWhen(logger, *address != "" && *accountName != "", "--address conflicts with --account")
+	from, err := nonce.ResolveAddress(*accountName, *accountIndex, *address)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	net, err := database.QueryNetworkOrCurrent(*networkName)
+	utils.ExitWhenErr(logger, err, "query network: %v error: %v", *networkName, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+	defer client.Close()
+
+	status, err := transaction.GetNonceStatus(ctx, client, net.Name, from)
+	utils.ExitWhenErr(logger, err, "get nonce status error: %v", err)
+
+	fmt.Printf("Network:       %s\n", net.Name)
+	fmt.Printf("Address:       %s\n", from.Hex())
+	fmt.Printf("Latest Nonce:  %d (confirmed)\n", status.Latest)
+	fmt.Printf("Pending Nonce: %d (pool)\n", status.Pending)
+	fmt.Printf("Next Nonce:    %d (database)\n", status.Next)
+	fmt.Printf("Gaps:          %v\n", status.Gaps)
+
+	if len(status.Allocations) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NONCE\tSTATUS\tTX HASH\tUPDATED AT")
+	for _, allocation := range status.Allocations {
+		txHash := allocation.TxHash
+		if txHash == "" {
+			txHash = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", allocation.Nonce, allocation.Status, txHash, allocation.UpdatedAt.Local().Format(time.RFC3339))
+	}
+	w.Flush()
+}
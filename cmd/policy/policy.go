@@ -0,0 +1,44 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+*/
+package policy
+
+import (
+	"fmt"
+	cmd "met/cmd"
+	database "met/database"
+
+	"github.com/spf13/cobra"
+)
+
+// PolicyCmd represents the policy command
+var PolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "account signing policy",
+	Long:  `per-account signing policy, checked before any transaction is signed`,
+	Run:   nil,
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(PolicyCmd)
+}
+
+func ShowPolicy(policy database.Policy) {
+	fmt.Printf("Account %s\n", policy.AccountName)
+	fmt.Printf("Max Value: %s\n", orUnlimited(policy.MaxValue, "ETH"))
+	fmt.Printf("Daily Cap: %s\n", orUnlimited(policy.DailyCap, "ETH"))
+	fmt.Printf("Allowed To: %s\n", orUnlimited(policy.AllowedTo, ""))
+	fmt.Printf("Allowed Selectors: %s\n", orUnlimited(policy.AllowedSelectors, ""))
+	fmt.Printf("Max Fee Per Gas: %s\n", orUnlimited(policy.MaxFeePerGas, "Gwei"))
+	fmt.Println()
+}
+
+func orUnlimited(value string, unit string) string {
+	if value == "" {
+		return "unlimited"
+	}
+	if unit == "" {
+		return value
+	}
+	return fmt.Sprintf("%s %s", value, unit)
+}
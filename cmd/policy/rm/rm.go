@@ -0,0 +1,39 @@
+package rm
+
+import (
+	"met/cmd/policy"
+	database "met/database"
+	transaction "met/transaction"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:     "rm",
+	Aliases: []string{"remove", "delete", "del"},
+	Short:   "rm account policy",
+	Long:    "rm account policy",
+	Run:     removePolicy,
+}
+
+var account *string
+
+func init() {
+	policy.PolicyCmd.AddCommand(rmCmd)
+
+	account = rmCmd.Flags().String("account", "", "account name")
+}
+
+func removePolicy(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("removePolicy")
+
+	utils.ExitWhen(logger, *account == "", "need account")
+
+	// 删除策略需要账户密码
+	err := transaction.ConfirmAccountPassword(*account, "remove policy")
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	err = database.RemovePolicy(*account)
+	utils.ExitWhenErr(logger, err, "remove policy error: %s", err)
+}
@@ -0,0 +1,105 @@
+package set
+
+import (
+	"met/cmd/policy"
+	database "met/database"
+	transaction "met/transaction"
+	utils "met/utils"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "set account policy",
+	Long:  "set account policy, replace the old one if exists",
+	Run:   setPolicy,
+}
+
+var (
+	account          *string
+	selectors        *string
+	maxValue         *string
+	dailyCap         *string
+	allowedTo        *string
+	allowedSelectors *string
+	maxFeePerGas     *string
+)
+
+func init() {
+	policy.PolicyCmd.AddCommand(setCmd)
+
+	account = setCmd.Flags().String("account", "", "account name")
+	selectors = setCmd.Flags().String("accounts", "", "account selectors separated by comma, eg: tag:bots,group:airdrop (conflicts with --account)")
+	maxValue = setCmd.Flags().String("max-value", "", "max native value per tx (unit: eth), empty for unlimited")
+	dailyCap = setCmd.Flags().String("daily-cap", "", "max native value sent in 24 hours per network (unit: eth), empty for unlimited")
+	allowedTo = setCmd.Flags().String("allowed-to", "", "allowed recipient or contract addresses separated by comma, empty for unlimited")
+	allowedSelectors = setCmd.Flags().String("allowed-selectors", "", "allowed method selectors separated by comma (eg: 0xa9059cbb), empty for unlimited")
+	maxFeePerGas = setCmd.Flags().String("max-fee", "", "max gasPrice or gasFeeCap (unit: gwei), empty for unlimited")
+}
+
+func setPolicy(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("setPolicy")
+
+	utils.ExitWhen(logger, *account == "" && *selectors == "", "need account")
+	utils.ExitWhen(logger, *account != "" && *selectors != "", "--accounts conflicts with --account")
+
+	accountNames := []string{*account}
+	if *selectors != "" {
+		selected, err := database.SelectAccounts(*selectors)
+		utils.ExitWhenErr(logger, err, "select accounts error: %s", err)
+
+		// 策略与HD index无关，去掉重复的账户
+		accountNames = nil
+		seen := make(map[string]bool)
+		for _, acc := range selected {
+			if !seen[acc.Name] {
+				seen[acc.Name] = true
+				accountNames = append(accountNames, acc.Name)
+			}
+		}
+	}
+
+	if *maxValue != "" {
+		_, err := utils.ParseUnits(*maxValue, utils.UnitEth)
+		utils.ExitWhenErr(logger, err, "invalid max value: %v", err)
+	}
+	if *dailyCap != "" {
+		_, err := utils.ParseUnits(*dailyCap, utils.UnitEth)
+		utils.ExitWhenErr(logger, err, "invalid daily cap: %v", err)
+	}
+	if *maxFeePerGas != "" {
+		_, err := utils.ParseUnits(*maxFeePerGas, utils.UnitGwei)
+		utils.ExitWhenErr(logger, err, "invalid max fee: %v", err)
+	}
+	for _, address := range strings.Split(*allowedTo, ",") {
+		address = strings.TrimSpace(address)
+		utils.ExitWhen(logger, address != "" && !utils.IsValidAddress(address), "invalid address: %v", address)
+	}
+	for _, selector := range strings.Split(*allowedSelectors, ",") {
+		selector = strings.TrimPrefix(strings.TrimSpace(selector), "0x")
+		utils.ExitWhen(logger, selector != "" && len(selector) != 8, "invalid selector: %v", selector)
+	}
+
+	// 修改策略需要账户密码，否则可以随意放宽限制
+	for _, accountName := range accountNames {
+		err := transaction.ConfirmAccountPassword(accountName, "set policy")
+		utils.ExitWhenErr(logger, err, "%v", err)
+	}
+
+	for _, accountName := range accountNames {
+		p := database.Policy{
+			AccountName:      accountName,
+			MaxValue:         *maxValue,
+			DailyCap:         *dailyCap,
+			AllowedTo:        *allowedTo,
+			AllowedSelectors: *allowedSelectors,
+			MaxFeePerGas:     *maxFeePerGas,
+		}
+		err := database.SetPolicy(&p)
+		utils.ExitWhenErr(logger, err, "set policy error: %s", err)
+
+		policy.ShowPolicy(p)
+	}
+}
@@ -0,0 +1,42 @@
+package show
+
+import (
+	"met/cmd/policy"
+	database "met/database"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:     "show",
+	Aliases: []string{"list"},
+	Short:   "show account policy",
+	Long:    "show account policy",
+	Run:     showPolicy,
+}
+
+var account *string
+
+func init() {
+	policy.PolicyCmd.AddCommand(showCmd)
+
+	account = showCmd.Flags().String("account", "", "show specify account policy instead of all policies")
+}
+
+func showPolicy(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("showPolicy")
+
+	if *account != "" {
+		p, err := database.QueryPolicy(*account)
+		utils.ExitWhenErr(logger, err, "query policy of account: %s error: %s", *account, err)
+		policy.ShowPolicy(p)
+	} else {
+		policies, err := database.QueryAllPolicies()
+		utils.ExitWhenErr(logger, err, "query policies error: %s", err)
+
+		for i := range policies {
+			policy.ShowPolicy(policies[i])
+		}
+	}
+}
@@ -4,12 +4,20 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"met/config"
+	database "met/database"
+	setup "met/setup"
 	utils "met/utils"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+const (
+	// 带有这个annotation的命令及其子命令启动时不自动执行数据库migration
+	SkipMigrateAnnotation = "skipMigrate"
+)
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:               "my-ether-tool",
@@ -35,7 +43,9 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.my-ether-tool.yaml)")
+	RootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/.met/config.yaml, env: MET_CONFIG)")
+	RootCmd.PersistentFlags().String("profile", "", "profile in config file (env: MET_PROFILE)")
+	RootCmd.PersistentFlags().String("db", "", "database path (default is $HOME/.met/met.db, env: MET_DB)")
 	RootCmd.PersistentFlags().String("loglevel", "", "log level: trace debug info warn error fatal (loglevel has high priority than verbose)")
 	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose message (debug loglevel)")
 
@@ -44,30 +54,67 @@ func init() {
 	// RootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// rootPreRun 按 flags > 环境变量(MET_*) > profile > 默认值 确定设置，然后设置日志和数据库
 func rootPreRun(cmd *cobra.Command, args []string) error {
-	loglevel := cmd.Flag("loglevel").Value.String()
-	verbose, err := cmd.Flags().GetBool("verbose")
+	flags := cmd.Flags()
+
+	configPath, _ := flags.GetString("config")
+	required := configPath != ""
+	if configPath == "" {
+		configPath = os.Getenv(config.EnvPrefix + "CONFIG")
+		required = configPath != ""
+	}
+	if configPath == "" {
+		configPath = config.DefaultPath()
+	}
+	file, err := config.Load(configPath, required)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	var levelStr string
+	profile, _ := flags.GetString("profile")
+	db, _ := flags.GetString("db")
+	loglevel, _ := flags.GetString("loglevel")
+	verbose, err := flags.GetBool("verbose")
+	if err != nil {
+		panic(err)
+	}
 
 	// loglevel 优先级高于verbose
-	if loglevel != "" {
-		levelStr = loglevel
-	} else {
-		if verbose {
-			levelStr = "debug"
-		} else {
-			levelStr = "info"
-		}
+	if loglevel == "" && verbose {
+		loglevel = "debug"
 	}
 
-	err = utils.SetLogger(levelStr)
+	settings, profile, err := config.Resolve(file, profile, config.FromEnv(), config.Settings{Db: db, LogLevel: loglevel})
 	if err != nil {
 		return err
 	}
+	if settings.LogLevel == "" {
+		settings.LogLevel = "info"
+	}
+	config.SetCurrent(settings)
+
+	err = utils.SetLogger(settings.LogLevel)
+	if err != nil {
+		return err
+	}
+	if profile != "" {
+		logger := utils.GetLogger("rootPreRun")
+		logger.Debug().Msgf("use profile: %v", profile)
+	}
+
+	setup.SetupDb(settings.Db, !skipMigrate(cmd))
+	database.SetDefaultNetwork(settings.Network)
+	database.SetDefaultAccount(settings.Account)
 
 	return nil
 }
+
+func skipMigrate(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[SkipMigrateAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"met/cmd/tx"
+	database "met/database"
+	transaction "met/transaction"
+	ttypes "met/types"
+	utils "met/utils"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "send txs from csv",
+	Long: `send txs from a csv file with header, columns: to,value,data or to,token,amount
+value (unit: eth) and amount (unit: token) are human readable, lines starting with # are ignored
+progress is saved to database, run the same file again to resume without sending a row twice`,
+	Run: runBatch,
+}
+
+var (
+	account      *string
+	accountIndex *uint
+	network      *string
+
+	file   *string
+	output *string
+	window *int
+	// 单位秒，0表示一直等待
+	timeout *uint
+	// 忽略上次未完成的进度，重新发送所有行
+	restart *bool
+
+	gasMode  *string
+	gasRatio *string
+	gasPrice *string
+	tipCap   *string
+	feeCap   *string
+
+	noconfirm      *bool
+	overridePolicy *bool
+	simulateOnly   *bool
+)
+
+func init() {
+	tx.TxCmd.AddCommand(batchCmd)
+
+	account = batchCmd.Flags().String("account", "", "account to be used to send txs,use current if empty")
+	accountIndex = batchCmd.Flags().Uint("account-index", 0, "account index to be used to send txs")
+	network = batchCmd.Flags().String("network", "", "used network, use current if empty")
+
+	file = batchCmd.Flags().String("file", "", "csv file of txs")
+	output = batchCmd.Flags().String("output", "", "result csv file, use <file>.result.csv if empty")
+	window = batchCmd.Flags().Int("window", 10, "max txs waiting to be mined at the same time")
+	timeout = batchCmd.Flags().Uint("timeout", 0, "max time to wait for txs to be mined(unit: s), keep waiting while txs are in the pool if 0 (run again to resume)")
+	restart = batchCmd.Flags().Bool("new", false, "start a new batch instead of resuming the last run of the same file (rows already sent will be sent again)")
+
+	gasMode = batchCmd.Flags().String("gasMode", "", "gas mode(eg: auto,legacy,1559), use network default or auto if empty")
+	gasRatio = batchCmd.Flags().String("gasRatio", "", "gasRatio, use network default if empty")
+	gasPrice = batchCmd.Flags().String("gasPrice", "", "gas price(gwei)")
+	tipCap = batchCmd.Flags().String("tipCap", "", "tipCap(gwei)")
+	feeCap = batchCmd.Flags().String("feeCap", "", "feeCap(gwei)")
+
+	noconfirm = batchCmd.Flags().BoolP("noconfirm", "y", false, "do not need to confirm")
+	overridePolicy = batchCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+	simulateOnly = batchCmd.Flags().Bool("simulate-only", false, "validate and simulate all rows at pending block and exit without sending")
+}
+
+func runBatch(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("runBatch")
+
+	utils.ExitWhen(logger, *file == "", "missing --file")
+	utils.ExitWhen(logger, *window < 1, "invalid window: %v", *window)
+
+	mode, err := ttypes.ParseGasMode(*gasMode)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	opts := &transaction.BatchOptions{
+		Window:   *window,
+		Timeout:  time.Duration(*timeout) * time.Second,
+		GasMode:  mode,
+		GasRatio: *gasRatio,
+		GasPrice: *gasPrice,
+		TipCap:   *tipCap,
+		FeeCap:   *feeCap,
+	}
+
+	path, err := filepath.Abs(*file)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	content, err := os.ReadFile(path)
+	utils.ExitWhenErr(logger, err, "read file error: %v", err)
+	sum := sha256.Sum256(content)
+	fileHash := hex.EncodeToString(sum[:])
+
+	resultPath := *output
+	if resultPath == "" {
+		resultPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".result.csv"
+	}
+
+	// account
+	acc, err := database.QueryAccountOrCurrent(*account, *accountIndex)
+	utils.ExitWhenErr(logger, err, "load account error: %s", err)
+
+	details, err := ttypes.AccountToDetails(acc)
+	utils.ExitWhenErr(logger, err, "calculate address error: %s", err)
+
+	privateKeyStr, err := details.PrivateKey()
+	utils.ExitWhenErr(logger, err, "get account private key error: %s", err)
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyStr, "0x"))
+	utils.ExitWhenErr(logger, err, "parse privateKey error: %s", err)
+
+	fromStr, err := details.Address()
+	utils.ExitWhenErr(logger, err, "get account address error: %s", err)
+	from := common.HexToAddress(fromStr)
+
+	// network
+	net, err := database.QueryNetworkOrCurrent(*network)
+	utils.ExitWhenErr(logger, err, "load network error: %s", err)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dialCtx, dialCancel := context.WithTimeout(ctx, net.RequestTimeout())
+	defer dialCancel()
+	client, err := utils.DialRpcs(dialCtx, net.Endpoints(), net.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+	defer client.Close()
+
+	logger.Info().Msgf("Account Name: %s", details.Name)
+	logger.Info().Msgf("Account Index: %v", details.CurrentIndex)
+	logger.Info().Msgf("Address: %s", from.Hex())
+	logger.Info().Msgf("Network Name: %s", net.Name)
+	logger.Info().Msgf("Network RPC: %s", utils.DisplayRpcUrl(net.Rpc))
+
+	// 同一个账户在同一个网络上执行过这个文件时从中断处恢复
+	var (
+		batch *database.Batch
+		items []database.BatchItem
+	)
+	if !*restart {
+		batch, err = database.QueryLatestBatch(net.Name, from.Hex(), path)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			batch = nil
+		} else {
+			utils.ExitWhenErr(logger, err, "query batch error: %v", err)
+		}
+	}
+	if batch != nil {
+		utils.ExitWhen(logger, batch.FileHash != fileHash, "file changed since batch #%v was created at %v, use --new to start a new batch (rows already sent will be sent again)", batch.ID, batch.CreatedAt.Local())
+		items, err = database.QueryBatchItems(batch.ID)
+		utils.ExitWhenErr(logger, err, "query batch items error: %v", err)
+		logger.Info().Msgf("resume batch #%v created at %v", batch.ID, batch.CreatedAt.Local())
+	} else {
+		items, err = transaction.ReadBatchFile(bytes.NewReader(content))
+		utils.ExitWhenErr(logger, err, "read batch file error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	var pending []*database.BatchItem
+	for i := range items {
+		counts[items[i].Status]++
+		if items[i].Status == database.BatchItemPending {
+			pending = append(pending, &items[i])
+		}
+	}
+	if batch != nil && len(pending) == 0 && counts[database.BatchItemSigned]+counts[database.BatchItemSent] == 0 {
+		writeResult(resultPath, items)
+		logger.Info().Msgf("batch #%v is already completed, use --new to send it again", batch.ID)
+		return
+	}
+
+	// 发送之前检查所有待发送的行
+	estimate, err := transaction.ValidateBatch(ctx, client, net, from, pending, opts)
+	utils.ExitWhenErr(logger, err, "invalid batch:\n%v", err)
+
+	err = transaction.CheckBatchPolicy(details.Name, net.Name, transaction.BatchPolicyTxs(estimate), *overridePolicy)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	logger.Info().Msgf(summary(net, items, counts, estimate))
+
+	if *simulateOnly {
+		return
+	}
+
+	if !*noconfirm {
+		input, err := utils.ReadChar("Send ? [y/N] ")
+		utils.ExitWhenErr(logger, err, "read input error: %s", err)
+		if input != 'y' {
+			os.Exit(0)
+		}
+	}
+
+	if batch == nil {
+		batch = &database.Batch{
+			Network:      net.Name,
+			From:         from.Hex(),
+			File:         path,
+			FileHash:     fileHash,
+			AccountName:  details.Name,
+			AccountIndex: details.CurrentIndex,
+		}
+		err = database.AddBatch(batch, items)
+		utils.ExitWhenErr(logger, err, "save batch error: %v", err)
+		logger.Info().Msgf("created batch #%v", batch.ID)
+	}
+
+	runErr := transaction.RunBatch(ctx, client, net, batch, items, estimate.Calls, privateKey, opts)
+
+	writeResult(resultPath, items)
+	utils.ExitWhenErr(logger, runErr, "batch #%v interrupted: %v, run again to resume", batch.ID, runErr)
+
+	counts = make(map[string]int)
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	logger.Info().Msgf("batch #%v completed, mined: %v failed: %v replaced: %v", batch.ID, counts[database.BatchItemMined], counts[database.BatchItemFailed], counts[database.BatchItemReplaced])
+}
+
+func summary(net *database.Network, items []database.BatchItem, counts map[string]int, estimate *transaction.BatchEstimate) string {
+	value, _ := utils.FormatUnits(estimate.Value.String(), utils.UnitEth)
+	fee, _ := utils.FormatUnits(estimate.Fee.String(), utils.UnitEth)
+	balance := "-"
+	if estimate.Balance != nil {
+		balance, _ = utils.FormatUnits(estimate.Balance.String(), utils.UnitEth)
+	}
+	feePerGas := "-"
+	if estimate.FeePerGas != nil {
+		feePerGas, _ = utils.Wei2Gwei(estimate.FeePerGas.String())
+	}
+
+	info := fmt.Sprintf(`
+Batch to be sent
+Rows:                %v (to send: %v, resumed: %v, done: %v)
+Value:               %s %s
+Max Fee:             %s %s (gas: %v, fee per gas: %s Gwei)
+Balance:             %s %s
+`,
+		len(items), counts[database.BatchItemPending],
+		counts[database.BatchItemSigned]+counts[database.BatchItemSent],
+		counts[database.BatchItemMined]+counts[database.BatchItemFailed]+counts[database.BatchItemReplaced],
+		value, net.Symbol,
+		fee, net.Symbol, estimate.Gas, feePerGas,
+		balance, net.Symbol)
+
+	for _, token := range estimate.Tokens {
+		amount, _ := utils.Erc20AmountToHuman(token.Amount.String(), strconv.Itoa(int(token.Decimals)))
+		tokenBalance, _ := utils.Erc20AmountToHuman(token.Balance.String(), strconv.Itoa(int(token.Decimals)))
+		info += fmt.Sprintf("Token:               %s amount: %s balance: %s\n", token.Token.Hex(), amount, tokenBalance)
+	}
+	return info
+}
+
+func writeResult(path string, items []database.BatchItem) {
+	logger := utils.GetLogger("writeResult")
+
+	f, err := os.Create(path)
+	utils.ExitWhenErr(logger, err, "create result file error: %v", err)
+	defer f.Close()
+
+	err = transaction.WriteBatchResult(f, items)
+	utils.ExitWhenErr(logger, err, "write result file error: %v", err)
+	logger.Info().Msgf("result: %v", path)
+}
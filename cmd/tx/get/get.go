@@ -0,0 +1,164 @@
+package get
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"met/cmd/tx"
+	database "met/database"
+	transaction "met/transaction"
+	utils "met/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <hash>",
+	Short: "get tx by hash",
+	Long:  "get transaction by hash, show type, fees, sender, receiver, value and decoded calldata",
+	Args:  cobra.ExactArgs(1),
+	Run:   getTransaction,
+}
+
+var (
+	network       *string
+	abiJson       *string
+	wait          *bool
+	confirmations *int8
+)
+
+func init() {
+	tx.TxCmd.AddCommand(getCmd)
+
+	network = getCmd.Flags().String("network", "", "used network, use current if empty")
+	abiJson = getCmd.Flags().String("abi", "", "abi JSON string used to decode calldata, available built-in abi: erc20 erc721 erc1155 (built-in abis are always tried)")
+	wait = getCmd.Flags().Bool("wait", false, "wait until the tx is mined")
+	confirmations = getCmd.Flags().Int8("confirmations", 0, "confirmations to wait for with --wait")
+}
+
+func getTransaction(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("getTransaction")
+
+	txHash, err := transaction.ParseTxHash(args[0])
+	utils.ExitWhenErr(logger, err, "%v", err)
+	utils.ExitWhen(logger, *confirmations < 0, "confirmations must not be negative")
+
+	abis, err := transaction.DecodeAbis(*abiJson)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	net, err := database.QueryNetworkOrCurrent(*network)
+	utils.ExitWhenErr(logger, err, "query network: %v error: %v", *network, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+	defer client.Close()
+
+	var receipt *types.Receipt
+	if *wait {
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), net.ReceiptTimeout())
+		defer waitCancel()
+
+		receipt, err = transaction.WaitTx(waitCtx, client, net, txHash, *confirmations)
+		utils.ExitWhenErr(logger, err, "wait tx: %v error: %v", txHash, err)
+	}
+
+	logger.Info().Msgf("query tx: %v", txHash)
+	t, pending, err := client.TransactionByHash(ctx, txHash)
+	utils.ExitWhen(logger, errors.Is(err, ethereum.NotFound), "tx: %v not found", txHash)
+	utils.ExitWhenErr(logger, err, "query tx: %v error: %v", txHash, err)
+
+	if receipt == nil && !pending {
+		receipt, err = client.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			logger.Warn().Msgf("query receipt: %v error: %v", txHash, err)
+		}
+	}
+
+	info, err := txInfo(net, t, receipt, abis)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	logger.Info().Msg(info)
+}
+
+func txInfo(net *database.Network, t *types.Transaction, receipt *types.Receipt, abis []*abi.ABI) (string, error) {
+	from, err := transaction.TxSender(t)
+	if err != nil {
+		return "", fmt.Errorf("recover sender error: %w", err)
+	}
+
+	to := "EMPTY (contract creation)"
+	if t.To() != nil {
+		to = t.To().Hex()
+	}
+
+	value, err := utils.FormatUnits(t.Value().String(), utils.UnitEth)
+	if err != nil {
+		return "", err
+	}
+	gasPrice, err := utils.Wei2Gwei(t.GasPrice().String())
+	if err != nil {
+		return "", err
+	}
+	tipCap, err := utils.Wei2Gwei(t.GasTipCap().String())
+	if err != nil {
+		return "", err
+	}
+	feeCap, err := utils.Wei2Gwei(t.GasFeeCap().String())
+	if err != nil {
+		return "", err
+	}
+
+	status := "pending"
+	if receipt != nil {
+		status = fmt.Sprintf("mined in block %v", receipt.BlockNumber)
+	}
+
+	call := "-"
+	if t.To() == nil && len(t.Data()) > 0 {
+		call = fmt.Sprintf("deploy: %d bytes", len(t.Data()))
+	} else if decoded, ok := transaction.DecodeCallWith(abis, t.Data()); ok {
+		call = decoded
+	} else if len(t.Data()) >= 4 {
+		call = fmt.Sprintf("unknown selector: 0x%x", t.Data()[:4])
+	}
+
+	return fmt.Sprintf(`
+Transaction
+Hash:                %s
+Status:              %s
+Type:                %s
+From:                %s
+To:                  %s
+Value:               %s (%s %s)
+Nonce:               %v
+ChainId:             %s
+GasLimit:            %v
+GasPrice:            %s (%s Gwei)
+GasTipCap:           %s (%s Gwei)
+GasFeeCap:           %s (%s Gwei)
+Data:                0x%s
+Call:                %s
+Tx Link:             %s
+`,
+		t.Hash().Hex(),
+		status,
+		transaction.TxTypeName(t.Type()),
+		from.Hex(),
+		to,
+		t.Value().String(), value, net.Symbol,
+		t.Nonce(),
+		t.ChainId().String(),
+		t.Gas(),
+		t.GasPrice().String(), gasPrice,
+		t.GasTipCap().String(), tipCap,
+		t.GasFeeCap().String(), feeCap,
+		hex.EncodeToString(t.Data()),
+		call,
+		net.TxLink(t.Hash().Hex())), nil
+}
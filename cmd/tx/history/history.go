@@ -0,0 +1,108 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"met/cmd/tx"
+	"met/config"
+	database "met/database"
+	utils "met/utils"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "list sent txs",
+	Long:  "list txs broadcast by met, run tx sync to update pending txs",
+	Run:   showHistory,
+}
+
+var (
+	account *string
+	network *string
+	status  *string
+	since   *string
+	until   *string
+	limit   *int
+	format  *string
+)
+
+func init() {
+	tx.TxCmd.AddCommand(historyCmd)
+
+	account = historyCmd.Flags().String("account", "", "filter by account name")
+	network = historyCmd.Flags().String("network", "", "filter by network name")
+	status = historyCmd.Flags().String("status", "", "filter by status: pending mined failed dropped replaced")
+	since = historyCmd.Flags().String("since", "", "filter by time, eg: 2024-01-02 or 2024-01-02T15:04:05Z")
+	until = historyCmd.Flags().String("until", "", "filter by time (exclusive), eg: 2024-01-03")
+	limit = historyCmd.Flags().Int("limit", 20, "show latest N txs (0 for all)")
+	format = historyCmd.Flags().String("format", "table", "output format: table json")
+}
+
+func showHistory(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("showHistory")
+
+	*format = config.Format(cmd.Flags(), *format, "table", "json")
+	utils.ExitWhen(logger, *format != "table" && *format != "json", "invalid format: %v", *format)
+
+	switch *status {
+	case "", database.TxStatusPending, database.TxStatusMined, database.TxStatusFailed, database.TxStatusDropped, database.TxStatusReplaced:
+	default:
+		logger.Fatal().Msgf("invalid status: %v", *status)
+	}
+
+	filter := database.TransactionFilter{
+		AccountName: *account,
+		Network:     *network,
+		Status:      *status,
+		Limit:       *limit,
+	}
+	var err error
+	if *since != "" {
+		filter.Since, err = utils.ParseTime(*since)
+		utils.ExitWhenErr(logger, err, "%v", err)
+	}
+	if *until != "" {
+		filter.Until, err = utils.ParseTime(*until)
+		utils.ExitWhenErr(logger, err, "%v", err)
+	}
+
+	txs, err := database.QueryTransactions(filter)
+	utils.ExitWhenErr(logger, err, "query transactions error: %v", err)
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(txs)
+		utils.ExitWhenErr(logger, err, "encode json error: %s", err)
+	case "table":
+		printTable(txs)
+	}
+}
+
+func printTable(txs []database.Transaction) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tNETWORK\tACCOUNT\tHASH\tNONCE\tSTATUS\tBLOCK\tSUMMARY")
+	for _, t := range txs {
+		account := t.AccountName
+		if account == "" {
+			account = t.From
+		}
+		block := "-"
+		if t.BlockNumber != 0 {
+			block = fmt.Sprintf("%d", t.BlockNumber)
+		}
+		status := t.Status
+		if t.ReplacedBy != "" {
+			status = fmt.Sprintf("%s by %s", t.Status, t.ReplacedBy)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			t.CreatedAt.Local().Format(time.RFC3339), t.Network, account, t.Hash, t.Nonce, status, block, t.Summary)
+	}
+	w.Flush()
+}
@@ -5,6 +5,7 @@ package tx
 
 import (
 	"bufio"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	ttypes "met/types"
 	utils "met/utils"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
@@ -50,6 +52,8 @@ var (
 	tipCap   *string
 	feeCap   *string
 
+	simulateOnly *bool
+
 	// explorer *string
 )
 
@@ -84,6 +88,7 @@ func init() {
 	tipCap = offsignCmd.Flags().String("tipCap", "", "tipCap(gwei)")
 	feeCap = offsignCmd.Flags().String("feeCap", "", "feeCap(gwei)")
 	eip1559 = offsignCmd.Flags().Bool("eip1559", true, "eip1559 switch")
+	simulateOnly = offsignCmd.Flags().Bool("simulate-only", false, "run eth_call at pending block and exit without signing")
 
 	// explorer = offsignCmd.Flags().String("explorer", "", "explorer url")
 }
@@ -97,22 +102,32 @@ func offsign(cmd *cobra.Command, args []string) {
 
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "load network error: %s", err)
-	rpc := net.Rpc
 
 	fmt.Printf("environment info:\n")
 	fmt.Printf("%-20s:%s\n", "network name", net.Name)
-	fmt.Printf("%-20s:%s\n", "network rpc", net.Rpc)
+	fmt.Printf("%-20s:%s\n", "network rpc", utils.DisplayRpcUrl(net.Rpc))
 
-	ctx, cancel := utils.DefaultTimeoutContext()
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
 	defer cancel()
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 	defer client.Close()
 
+	chainId, err := transaction.VerifyChainId(ctx, client, net)
+	utils.ExitWhenErr(logger, err, "verify chain id error: %v", err)
+	utils.ExitWhen(logger, *chainID != 0 && int64(*chainID) != chainId.Int64(), "chain id: %v mismatch network: %v chain id: %v", *chainID, net.Name, chainId)
+
 	tx, err := transaction.BuildTransaction(ctx, client, *from, *to, value, *data, *abi, *abiArgs, *gasLimit, *nonce, *chainID, "", *gasPrice, *tipCap, *feeCap, *eip1559, false)
 	utils.ExitWhenErr(logger, err, "build transaction error: %s", err)
 
+	// 签名之前在pending区块上模拟调用
+	_, err = transaction.SimulateSend(client, net, *from, *to, tx.Value(), tx.Data(), "", nil)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	if *simulateOnly {
+		return
+	}
+
 	signer := types.NewCancunSigner(tx.ChainId())
 	txHash := signer.Hash(tx)
 	fmt.Printf("Hash to be signed: %s\n", txHash)
@@ -141,32 +156,23 @@ func offsign(cmd *cobra.Command, args []string) {
 	utils.ExitWhenErr(logger, err, "Marshal transaction to binary error: %s", err)
 
 	txHex := "0x" + hex.EncodeToString(txBytes)
-	id, err := uuid.NewUUID()
-	utils.ExitWhenErr(logger, err, "create uuid error: %s", err)
-
-	jsonRpcData := ttypes.JsonRpcData{
-		JsonRpc: "2.0",
-		Method:  "eth_sendRawTransaction",
-		Params:  []string{txHex},
-		Id:      id.String(),
-	}
-	// send txHex to rpc
-	httpClient := utils.NewHttpClient(rpc, 3)
-	resp, err := httpClient.PostStruct(nil, &jsonRpcData)
-	utils.ExitWhenErr(logger, err, "Send raw transaction error: %s", err)
 
-	var jsonRpcResult ttypes.JsonRpcResult
-	err = json.NewDecoder(resp.Body).Decode(&jsonRpcResult)
-	utils.ExitWhenErr(logger, err, "decode json rpc result error: %s", err)
+	// 等待签名可能超过了ctx的超时时间，使用新的ctx，client保证发送到构建交易时的同一个rpc
+	sendCtx, sendCancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer sendCancel()
 
-	utils.ExitWhen(logger, jsonRpcResult.Id != id.String(), "json rpc id not match")
+	var sentHash common.Hash
+	err = client.Client().CallContext(sendCtx, &sentHash, "eth_sendRawTransaction", txHex)
+	utils.ExitWhenErr(logger, err, "Send raw transaction error: %s", err)
+	// 外部签名的交易没有账户
+	transaction.RecordTransaction("", 0, net.Name, tx)
 
-	explorer := net.Explorer
-	if explorer != "" {
-		explorer = strings.TrimSuffix(explorer, "/")
-		// fmt.Printf("Transaction link: %s/tx/%s\n", explorer, jsonRpcResult.Result)
-		logger.Info().Msgf("Transaction link: %s/tx/%x", explorer, jsonRpcResult.Result)
+	if net.Explorer != "" || net.Defaults.ExplorerUrl != "" {
+		logger.Info().Msgf("Transaction link: %s", net.TxLink(sentHash.Hex()))
 	} else {
-		json.NewEncoder(os.Stdout).Encode(&jsonRpcResult)
+		// 与之前直接发送json rpc请求时的输出相同
+		id, err := uuid.NewUUID()
+		utils.ExitWhenErr(logger, err, "create uuid error: %s", err)
+		json.NewEncoder(os.Stdout).Encode(&ttypes.JsonRpcResult{JsonRpc: "2.0", Id: id.String(), Result: sentHash.Hex()})
 	}
 }
@@ -0,0 +1,159 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"met/cmd/tx"
+	database "met/database"
+	transaction "met/transaction"
+	utils "met/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var receiptCmd = &cobra.Command{
+	Use:   "receipt <hash>",
+	Short: "get tx receipt by hash",
+	Long:  "get transaction receipt by hash, show status, gas used, effective fee and decoded logs",
+	Args:  cobra.ExactArgs(1),
+	Run:   getReceipt,
+}
+
+var (
+	network       *string
+	abiJson       *string
+	wait          *bool
+	confirmations *int8
+)
+
+func init() {
+	tx.TxCmd.AddCommand(receiptCmd)
+
+	network = receiptCmd.Flags().String("network", "", "used network, use current if empty")
+	abiJson = receiptCmd.Flags().String("abi", "", "abi JSON string used to decode logs, available built-in abi: erc20 erc721 erc1155 (built-in abis are always tried)")
+	wait = receiptCmd.Flags().Bool("wait", false, "wait until the tx is mined")
+	confirmations = receiptCmd.Flags().Int8("confirmations", 0, "confirmations to wait for with --wait")
+}
+
+func getReceipt(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("getReceipt")
+
+	txHash, err := transaction.ParseTxHash(args[0])
+	utils.ExitWhenErr(logger, err, "%v", err)
+	utils.ExitWhen(logger, *confirmations < 0, "confirmations must not be negative")
+
+	abis, err := transaction.DecodeAbis(*abiJson)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	net, err := database.QueryNetworkOrCurrent(*network)
+	utils.ExitWhenErr(logger, err, "query network: %v error: %v", *network, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+	defer client.Close()
+
+	var receipt *types.Receipt
+	if *wait {
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), net.ReceiptTimeout())
+		defer waitCancel()
+
+		receipt, err = transaction.WaitTx(waitCtx, client, net, txHash, *confirmations)
+		utils.ExitWhenErr(logger, err, "wait tx: %v error: %v", txHash, err)
+	} else {
+		logger.Info().Msgf("query receipt: %v", txHash)
+		receipt, err = client.TransactionReceipt(ctx, txHash)
+		utils.ExitWhen(logger, errors.Is(err, ethereum.NotFound), "receipt of tx: %v not found, the tx may be pending, use --wait to wait for it", txHash)
+		utils.ExitWhenErr(logger, err, "query receipt: %v error: %v", txHash, err)
+	}
+
+	info, err := receiptInfo(net, receipt, abis)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	logger.Info().Msg(info)
+}
+
+func receiptInfo(net *database.Network, receipt *types.Receipt, abis []*abi.ABI) (string, error) {
+	status := "failed (0)"
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		status = "success (1)"
+	}
+
+	contractAddress := "-"
+	if receipt.ContractAddress != (common.Address{}) {
+		contractAddress = receipt.ContractAddress.Hex()
+	}
+
+	gasPrice := "-"
+	fee := new(big.Int)
+	if receipt.EffectiveGasPrice != nil {
+		gwei, err := utils.Wei2Gwei(receipt.EffectiveGasPrice.String())
+		if err != nil {
+			return "", err
+		}
+		gasPrice = fmt.Sprintf("%s (%s Gwei)", receipt.EffectiveGasPrice, gwei)
+		fee.Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	}
+	// blob交易的blob gas费用
+	if receipt.BlobGasPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(receipt.BlobGasPrice, new(big.Int).SetUint64(receipt.BlobGasUsed)))
+	}
+	feeEth, err := utils.FormatUnits(fee.String(), utils.UnitEth)
+	if err != nil {
+		return "", err
+	}
+
+	var logs []string
+	for _, log := range receipt.Logs {
+		decoded, ok := transaction.DecodeLog(abis, log)
+		if !ok {
+			var topics []string
+			for _, topic := range log.Topics {
+				topics = append(topics, topic.Hex())
+			}
+			decoded = fmt.Sprintf("topics: [%s] data: 0x%x", strings.Join(topics, ", "), log.Data)
+		}
+		logs = append(logs, fmt.Sprintf("  [%d] %s %s", log.Index, log.Address.Hex(), decoded))
+	}
+	if len(logs) == 0 {
+		logs = append(logs, "  -")
+	}
+
+	return fmt.Sprintf(`
+Transaction Receipt
+Tx Hash:             %s
+Status:              %s
+Type:                %s
+Block Number:        %v
+Block Hash:          %s
+Tx Index:            %v
+Contract Address:    %s
+Gas Used:            %v
+Effective Gas Price: %s
+Effective Fee:       %s (%s %s)
+Tx Link:             %s
+Logs (%d):
+%s
+`,
+		receipt.TxHash.Hex(),
+		status,
+		transaction.TxTypeName(receipt.Type),
+		receipt.BlockNumber,
+		receipt.BlockHash.Hex(),
+		receipt.TransactionIndex,
+		contractAddress,
+		receipt.GasUsed,
+		gasPrice,
+		fee, feeEth, net.Symbol,
+		net.TxLink(receipt.TxHash.Hex()),
+		len(receipt.Logs),
+		strings.Join(logs, "\n")), nil
+}
@@ -0,0 +1,166 @@
+package tx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	database "met/database"
+	transaction "met/transaction"
+	ttypes "met/types"
+	utils "met/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ReplaceOptions speedup和cancel命令的参数
+type ReplaceOptions struct {
+	Hash string
+	// true: 发给自己的0 value交易 false: 相同内容提高fee
+	Cancel bool
+
+	Account      string
+	AccountIndex uint
+	Network      string
+
+	Bump string
+
+	Ledger           bool
+	LedgerDerivePath string
+
+	Noconfirm bool
+	// nil表示使用network的默认值
+	Confirmations  *int8
+	OverridePolicy bool
+}
+
+// ReplaceTransaction 使用相同nonce和更高的fee替换pending的交易，并等待原交易和替换交易中的一个上链
+func ReplaceTransaction(opts ReplaceOptions) {
+	logger := utils.GetLogger("ReplaceTransaction")
+
+	var (
+		err          error
+		privateKey   *ecdsa.PrivateKey
+		from         string
+		accountName  string
+		accountIndex uint
+
+		ledgerWallet  accounts.Wallet
+		ledgerAccount *accounts.Account
+	)
+
+	txHash, err := transaction.ParseTxHash(opts.Hash)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	percent, err := transaction.ParsePriceBump(opts.Bump)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	if opts.Ledger {
+		ledgerWallet, ledgerAccount, err = utils.ConnectLedger(opts.LedgerDerivePath)
+		utils.ExitWhenErr(logger, err, "connect ledger error: %s", err)
+		defer ledgerWallet.Close()
+
+		accountName = "ledger"
+		from = ledgerAccount.Address.Hex()
+	} else {
+		account, err := database.QueryAccountOrCurrent(opts.Account, opts.AccountIndex)
+		utils.ExitWhenErr(logger, err, "load account error: %s", err)
+
+		details, err := ttypes.AccountToDetails(account)
+		utils.ExitWhenErr(logger, err, "calculate address error: %s", err)
+
+		privateKeyStr, err := details.PrivateKey()
+		utils.ExitWhenErr(logger, err, "get account private key error: %s", err)
+
+		privateKey, err = crypto.HexToECDSA(strings.TrimPrefix(privateKeyStr, "0x"))
+		utils.ExitWhenErr(logger, err, "parse privateKey error: %s", err)
+
+		from, err = details.Address()
+		utils.ExitWhenErr(logger, err, "get account address error: %s", err)
+
+		accountName = details.Name
+		accountIndex = details.CurrentIndex
+	}
+
+	net, err := database.QueryNetworkOrCurrent(opts.Network)
+	utils.ExitWhenErr(logger, err, "load network error: %s", err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
+	defer client.Close()
+
+	logger.Info().Msgf("query tx: %v", txHash)
+	old, pending, err := client.TransactionByHash(ctx, txHash)
+	utils.ExitWhen(logger, errors.Is(err, ethereum.NotFound), "tx: %v not found, it may have been dropped", txHash)
+	utils.ExitWhenErr(logger, err, "query tx: %v error: %v", txHash, err)
+	if !pending {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			logger.Fatal().Msgf("tx: %v already mined in block: %v", txHash, receipt.BlockNumber)
+		}
+	}
+
+	sender, err := transaction.TxSender(old)
+	utils.ExitWhenErr(logger, err, "recover sender error: %v", err)
+	utils.ExitWhen(logger, sender != common.HexToAddress(from), "tx sender: %v does not match account: %v", sender.Hex(), from)
+
+	logger.Info().Msgf("Account Name: %s", accountName)
+	logger.Info().Msgf("Account Index: %v", accountIndex)
+	logger.Info().Msgf("Address: %s", from)
+	logger.Info().Msgf("Network Name: %s", net.Name)
+	logger.Info().Msgf("replace tx: %v nonce: %v type: %v bump: %v%%", txHash, old.Nonce(), transaction.TxTypeName(old.Type()), percent)
+
+	tx, err := transaction.BuildReplacement(ctx, client, old, sender, opts.Cancel, percent, opts.Ledger)
+	utils.ExitWhenErr(logger, err, "build replacement error: %v", err)
+
+	err = transaction.CheckMaxFee(net, tx)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	// 替换交易和原交易都可能上链，由WaitReplacement等待
+	noWait := int8(-1)
+	_, tx, err = transaction.SendTx(client, from, accountName, accountIndex, tx, false, opts.Ledger, ledgerWallet, ledgerAccount, privateKey, net, opts.Noconfirm, &noWait, opts.OverridePolicy)
+	utils.ExitWhenErr(logger, err, "send transaction error: %v", err)
+	logger.Info().Msgf("replacement tx: %v", tx.Hash())
+	logger.Info().Msgf("tx link: %v", net.TxLink(tx.Hash().Hex()))
+
+	var confirmations int8
+	if opts.Confirmations != nil {
+		confirmations = *opts.Confirmations
+	} else if net.Defaults.Confirmations != nil {
+		confirmations = *net.Defaults.Confirmations
+	}
+	if confirmations < 0 {
+		return
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), net.ReceiptTimeout())
+	defer waitCancel()
+
+	logger.Info().Msgf("waiting for tx: %v or replacement: %v", txHash, tx.Hash())
+	receipt, err := transaction.WaitReplacement(waitCtx, client, sender, tx.Nonce(), []common.Hash{txHash, tx.Hash()})
+	utils.ExitWhenErr(logger, err, "wait replacement error: %v", err)
+	transaction.RecordReceipt(receipt)
+
+	if receipt.TxHash == tx.Hash() {
+		logger.Info().Msgf("replacement tx mined: %v", receipt.TxHash)
+	} else {
+		logger.Warn().Msgf("original tx mined: %v, replacement: %v dropped", receipt.TxHash, tx.Hash())
+	}
+
+	if confirmations > 0 {
+		logger.Info().Msgf("waiting for %v confirmations..", confirmations)
+		err = transaction.WaitConfirmations(waitCtx, client, receipt.BlockNumber, uint64(confirmations))
+		if err != nil {
+			logger.Warn().Msgf("context done before confirmations completed")
+		}
+	}
+
+	utils.ShowReceipt(logger, receipt)
+	logger.Info().Msgf("tx link: %v", net.TxLink(receipt.TxHash.Hex()))
+}
@@ -1,8 +1,9 @@
 package tx
 
 import (
+	"context"
 	"crypto/ecdsa"
-	"fmt"
+	"math/big"
 	"met/cmd/tx"
 	database "met/database"
 	transaction "met/transaction"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/spf13/cobra"
 )
@@ -43,11 +45,15 @@ var (
 	gasLimitRatio *string
 
 	gasMode  *string
+	txType   *string
 	gasRatio *string
 	gasPrice *string
 	tipCap   *string
 	feeCap   *string
 
+	accessList *string
+	blobFiles  *[]string
+
 	noconfirm *bool
 
 	confirmations *int8
@@ -58,6 +64,9 @@ var (
 
 	ledger           *bool
 	ledgerDerivePath *string
+
+	overridePolicy *bool
+	simulateOnly   *bool
 )
 
 func init() {
@@ -72,8 +81,8 @@ func init() {
 	all = sendCmd.Flags().Bool("all", false, "send all ether")
 
 	// data or abi + method + args
-	data = sendCmd.Flags().String("data", "", "data of transaction, conflict with --abi")
-	abi = sendCmd.Flags().String("abi", "", "abi JSON string, conflict with --data, available built-in abi: erc20 erc721 erc1155")
+	data = sendCmd.Flags().String("data", "", "data of transaction, conflict with --method and --args")
+	abi = sendCmd.Flags().String("abi", "", "abi JSON string, used with --method or to decode simulation result and custom errors of --data, available built-in abi: erc20 erc721 erc1155")
 	method = sendCmd.Flags().String("method", "", "methodName, conflict with --data")
 	abiArgs = sendCmd.Flags().StringArray("args", nil, "arguments of abi( --args 0x... --args 200)")
 
@@ -83,22 +92,29 @@ func init() {
 	gasLimit = sendCmd.Flags().String("gasLimit", "", "gas limit")
 	gasLimitRatio = sendCmd.Flags().String("gasLimitRatio", "", "gas limit ratio")
 
-	gasMode = sendCmd.Flags().String("gasMode", "auto", "gas mode(eg: auto,legacy,1559)")
-	gasRatio = sendCmd.Flags().String("gasRatio", "", "gasRatio")
+	gasMode = sendCmd.Flags().String("gasMode", "", "gas mode(eg: auto,legacy,1559), use network default or auto if empty")
+	txType = sendCmd.Flags().String("txType", "", "tx type(0: legacy, 1: access list, 2: eip1559, 3: blob, needs --blob-file), independent of gas mode, decided by gas mode if empty (legacy: 1, eip1559: 2), fall back to 0 when type 1 or 2 is rejected")
+	gasRatio = sendCmd.Flags().String("gasRatio", "", "gasRatio, use network default if empty")
 	gasPrice = sendCmd.Flags().String("gasPrice", "", "gas price(gwei)")
 	tipCap = sendCmd.Flags().String("tipCap", "", "tipCap(gwei)")
 	feeCap = sendCmd.Flags().String("feeCap", "", "feeCap(gwei)")
 
+	accessList = sendCmd.Flags().String("access-list", "", "access list of type-1 and type-2 tx: auto (generate with eth_createAccessList and attach only when it saves gas) or json file ([{\"address\":\"0x..\",\"storageKeys\":[\"0x..\"]}])")
+	blobFiles = sendCmd.Flags().StringArray("blob-file", nil, "send a blob tx (type 3), each file is packed into one blob (max 126976 bytes, 31 bytes per field element), max blobs per tx depends on the fork and is checked by the node (--blob-file a.bin --blob-file b.bin)")
+
 	noconfirm = sendCmd.Flags().BoolP("noconfirm", "y", false, "do not need to confirm")
 
-	confirmations = sendCmd.Flags().Int8("confirmations", 0, "blocks of confirmation (N < 0: send tx without receipt. 0: send tx with receipt. N > 0: send tx with receipt and N blocks confirmations)")
+	confirmations = sendCmd.Flags().Int8("confirmations", 0, "blocks of confirmation (N < 0: send tx without receipt. 0: send tx with receipt. N > 0: send tx with receipt and N blocks confirmations), use network default if not set")
 
 	blockHeight = sendCmd.Flags().String("height", "", "send tx after block height")
-	blockHeightInterval = sendCmd.Flags().Uint("heightInterval", 2, "check block height interval(unit: ms)")
-	blockHeightTimeout = sendCmd.Flags().Uint("heightTimeout", 600, "check block height timeout(unit: s)")
+	blockHeightInterval = sendCmd.Flags().Uint("heightInterval", 2, "check block height interval when rpc does not support subscriptions(unit: ms)")
+	blockHeightTimeout = sendCmd.Flags().Uint("heightTimeout", 0, "check block height timeout(unit: s), use network receipt timeout or 600 if 0")
 
 	ledger = sendCmd.Flags().Bool("ledger", false, "use ledger to sign tx, this flag will ignore --account and --account-index")
 	ledgerDerivePath = sendCmd.Flags().String("ledgerDerivePath", "m/44'/60'/0'/0/0", "ledger derive path, works only when --ledger is true")
+
+	overridePolicy = sendCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+	simulateOnly = sendCmd.Flags().Bool("simulate-only", false, "run eth_call at pending block and exit without sending")
 }
 
 func sendTransaction(cmd *cobra.Command, args []string) {
@@ -149,10 +165,10 @@ func sendTransaction(cmd *cobra.Command, args []string) {
 	net, err := database.QueryNetworkOrCurrent(*network)
 	utils.ExitWhenErr(logger, err, "load network error: %s", err)
 
-	ctx, cancel := utils.DefaultTimeoutContext()
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
 	defer cancel()
 
-	client, err := utils.DialRpc(ctx, net.Rpc)
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
 	utils.ExitWhenErr(logger, err, "dial rpc error: %v", err)
 	defer client.Close()
 
@@ -161,32 +177,61 @@ func sendTransaction(cmd *cobra.Command, args []string) {
 	logger.Info().Msgf("Account Index: %v", accoutnIndex)
 	logger.Info().Msgf("Address: %s", from)
 	logger.Info().Msgf("Network Name: %s", net.Name)
-	logger.Info().Msgf("Network RPC: %s", net.Rpc)
+	logger.Info().Msgf("Network RPC: %s", utils.DisplayRpcUrl(net.Rpc))
 
-	utils.ExitWhen(logger, *data != "" && (*abi != "" || len(*abiArgs) > 0), "--data conflicts with --abi and --args")
+	utils.ExitWhen(logger, *data != "" && (*method != "" || len(*abiArgs) > 0), "--data conflicts with --method and --args")
 
 	input, err := transaction.ParseInput(*data, *abi, *method, *abiArgs...)
 	utils.ExitWhenErr(logger, err, "%v", err)
 
-	mode := ttypes.GasMode(ttypes.GasMode_value[*gasMode])
+	mode, err := ttypes.ParseGasMode(*gasMode)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	typ, err := ttypes.ParseTxType(*txType)
+	utils.ExitWhenErr(logger, err, "%v", err)
+
+	// 没有指定--confirmations时使用network的默认值
+	var txConfirmations *int8
+	if cmd.Flags().Changed("confirmations") {
+		txConfirmations = confirmations
+	}
 
 	// wait block height
-	err = transaction.WaitBlock(client, *blockHeight, *blockHeightInterval, *blockHeightTimeout)
+	err = transaction.WaitBlock(client, net, *blockHeight, *blockHeightInterval, *blockHeightTimeout)
 	utils.ExitWhenErr(logger, err, "WaitBlock error: %v", err)
 
+	// blob，simulate时需要blob hashes
+	var sidecar *types.BlobTxSidecar
+	if len(*blobFiles) > 0 {
+		sidecar, err = transaction.ReadBlobFiles(*blobFiles)
+		utils.ExitWhenErr(logger, err, "%v", err)
+	}
+
+	// simulate，--all时value在构造交易时才能确定
+	var simValue *big.Int
+	if !*all && *value != "" {
+		simValue, err = utils.ParseUnits(*value, utils.UnitEth)
+		utils.ExitWhenErr(logger, err, "parse value: %v error: %v", *value, err)
+	}
+	_, err = transaction.SimulateSend(client, net, from, *to, simValue, input, *abi, sidecar)
+	utils.ExitWhenErr(logger, err, "%v", err)
+	if *simulateOnly {
+		return
+	}
+
 	// build tx
-	tx, err := transaction.BuildTx(client, from, *to, value, input, *ledger, mode, *nonce, *chainID, *gasLimit, *gasLimitRatio, *gasRatio, *gasPrice, *tipCap, *feeCap, *all)
+	tx, err := transaction.BuildTx(client, net, from, *to, value, input, *ledger, mode, typ, *nonce, *chainID, *gasLimit, *gasLimitRatio, *gasRatio, *gasPrice, *tipCap, *feeCap, *all, *accessList, sidecar)
 	utils.ExitWhenErr(logger, err, "build tx error: %s", err)
 
 	// send tx
-	receipt, tx, err := transaction.SendTx(client, from, tx, *ledger, ledgerWallet, ledgerAccount, privateKey, net, *noconfirm, *confirmations)
+	receipt, tx, err := transaction.SendTx(client, from, accountName, accoutnIndex, tx, *nonce == "", *ledger, ledgerWallet, ledgerAccount, privateKey, net, *noconfirm, txConfirmations, *overridePolicy)
 	utils.ExitWhenErr(logger, err, "send transaction error: %v", err)
 
 	if receipt != nil {
 		utils.ShowReceipt(logger, receipt)
 	}
 
-	link := fmt.Sprintf("%v/tx/%v", net.Explorer, tx.Hash())
+	link := net.TxLink(tx.Hash().Hex())
 	logger.Info().Msgf("tx link: %v", link)
 
 }
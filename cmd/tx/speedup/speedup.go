@@ -0,0 +1,70 @@
+package speedup
+
+import (
+	"met/cmd/tx"
+	transaction "met/transaction"
+
+	"github.com/spf13/cobra"
+)
+
+var speedupCmd = &cobra.Command{
+	Use:   "speedup <hash>",
+	Short: "speed up a pending tx",
+	Long:  "re-sign a pending tx with the same nonce and payload and bumped fees",
+	Args:  cobra.ExactArgs(1),
+	Run:   speedupTransaction,
+}
+
+var (
+	account      *string
+	accountIndex *uint
+	network      *string
+
+	bump *string
+
+	noconfirm     *bool
+	confirmations *int8
+
+	ledger           *bool
+	ledgerDerivePath *string
+
+	overridePolicy *bool
+)
+
+func init() {
+	tx.TxCmd.AddCommand(speedupCmd)
+
+	account = speedupCmd.Flags().String("account", "", "account which sent the tx, use current if empty")
+	accountIndex = speedupCmd.Flags().Uint("account-index", 0, "account index which sent the tx")
+	network = speedupCmd.Flags().String("network", "", "used network, use current if empty")
+
+	bump = speedupCmd.Flags().String("bump", transaction.DefaultPriceBump, "fee bump over the pending tx, at least 10%")
+
+	noconfirm = speedupCmd.Flags().BoolP("noconfirm", "y", false, "do not need to confirm")
+	confirmations = speedupCmd.Flags().Int8("confirmations", 0, "blocks of confirmation (N < 0: do not wait. 0: wait until the tx or its replacement is mined. N > 0: and N blocks confirmations), use network default if not set")
+
+	ledger = speedupCmd.Flags().Bool("ledger", false, "use ledger to sign tx, this flag will ignore --account and --account-index")
+	ledgerDerivePath = speedupCmd.Flags().String("ledgerDerivePath", "m/44'/60'/0'/0/0", "ledger derive path, works only when --ledger is true")
+
+	overridePolicy = speedupCmd.Flags().Bool("override-policy", false, "ignore account policy violations (need account password)")
+}
+
+func speedupTransaction(cmd *cobra.Command, args []string) {
+	opts := tx.ReplaceOptions{
+		Hash:             args[0],
+		Cancel:           false,
+		Account:          *account,
+		AccountIndex:     *accountIndex,
+		Network:          *network,
+		Bump:             *bump,
+		Ledger:           *ledger,
+		LedgerDerivePath: *ledgerDerivePath,
+		Noconfirm:        *noconfirm,
+		OverridePolicy:   *overridePolicy,
+	}
+	// 没有指定--confirmations时使用network的默认值
+	if cmd.Flags().Changed("confirmations") {
+		opts.Confirmations = confirmations
+	}
+	tx.ReplaceTransaction(opts)
+}
@@ -0,0 +1,81 @@
+package sync
+
+import (
+	"context"
+	"met/cmd/tx"
+	database "met/database"
+	transaction "met/transaction"
+	utils "met/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "update pending txs",
+	Long:  "update pending txs in tx history to mined, failed, dropped or replaced",
+	Run:   syncTransactions,
+}
+
+var (
+	network *string
+	all     *bool
+)
+
+func init() {
+	tx.TxCmd.AddCommand(syncCmd)
+
+	network = syncCmd.Flags().String("network", "", "network name, use current if empty")
+	all = syncCmd.Flags().Bool("all", false, "sync pending txs of all networks")
+}
+
+func syncTransactions(cmd *cobra.Command, args []string) {
+	logger := utils.GetLogger("syncTransactions")
+
+	utils.ExitWhen(logger, *all && *network != "", "--all conflicts with --network")
+
+	var networks []database.Network
+	if *all {
+		var err error
+		networks, err = database.QueryAllNetworks()
+		utils.ExitWhenErr(logger, err, "query networks error: %s", err)
+	} else {
+		net, err := database.QueryNetworkOrCurrent(*network)
+		utils.ExitWhenErr(logger, err, "query network: %v error: %s", *network, err)
+		networks = append(networks, *net)
+	}
+
+	for _, net := range networks {
+		pending, err := database.QueryTransactions(database.TransactionFilter{Network: net.Name, Status: database.TxStatusPending})
+		utils.ExitWhenErr(logger, err, "query pending transactions error: %v", err)
+		if len(pending) == 0 {
+			logger.Info().Msgf("network: %v no pending tx", net.Name)
+			continue
+		}
+
+		logger.Info().Msgf("network: %v sync %v pending txs", net.Name, len(pending))
+		updated, err := syncNetwork(&net, pending)
+		for _, t := range updated {
+			if t.ReplacedBy != "" {
+				logger.Info().Msgf("tx: %v nonce: %v %v by %v", t.Hash, t.Nonce, t.Status, t.ReplacedBy)
+			} else {
+				logger.Info().Msgf("tx: %v nonce: %v %v", t.Hash, t.Nonce, t.Status)
+			}
+		}
+		utils.ExitWhenErr(logger, err, "sync network: %v error: %v", net.Name, err)
+		logger.Info().Msgf("network: %v updated: %v still pending: %v", net.Name, len(updated), len(pending)-len(updated))
+	}
+}
+
+func syncNetwork(net *database.Network, pending []database.Transaction) ([]database.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	client, err := utils.DialRpcs(ctx, net.Endpoints(), net.Transport)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	return transaction.SyncTransactions(ctx, client, pending)
+}
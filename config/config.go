@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	utils "met/utils"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// 环境变量前缀，eg: MET_PROFILE MET_DB MET_NETWORK
+	EnvPrefix = "MET_"
+
+	// 旧版本使用的数据库路径环境变量，优先级低于MET_DB
+	LegacyDbEnv = "met_db"
+)
+
+// Settings 可以在配置文件顶层、profile、环境变量和命令行flag中设置，空值表示未设置
+type Settings struct {
+	// 数据库路径，默认~/.met/met.db
+	Db string `yaml:"db,omitempty"`
+	// 没有指定--network时使用的network，默认数据库中的当前network
+	Network string `yaml:"network,omitempty"`
+	// 没有指定--account时使用的account，默认数据库中的当前account
+	Account string `yaml:"account,omitempty"`
+	// trace debug info warn error fatal，默认info
+	LogLevel string `yaml:"loglevel,omitempty"`
+	// 支持--format的命令没有指定时使用，eg: json table csv
+	Output string `yaml:"output,omitempty"`
+}
+
+// File ~/.met/config.yaml，顶层设置对所有profile生效，profile中的设置覆盖顶层设置
+//
+//	profile: work
+//	loglevel: info
+//	profiles:
+//	  work:
+//	    db: ~/.met/work.db
+//	    network: mainnet
+//	    account: deployer
+//	  personal:
+//	    db: ~/.met/personal.db
+//	    output: json
+type File struct {
+	Settings `yaml:",inline"`
+	// 没有指定--profile和MET_PROFILE时使用的profile
+	Profile  string              `yaml:"profile,omitempty"`
+	Profiles map[string]Settings `yaml:"profiles,omitempty"`
+}
+
+var (
+	current Settings
+)
+
+// Current 当前命令使用的设置，在root命令的PersistentPreRun中设置
+func Current() Settings {
+	return current
+}
+
+func SetCurrent(settings Settings) {
+	current = settings
+}
+
+// Format 命令没有指定--format时，如果命令支持设置中的output则使用它，否则使用flag的默认值
+func Format(flags *pflag.FlagSet, value string, supported ...string) string {
+	if flags.Changed("format") || !slices.Contains(supported, current.Output) {
+		return value
+	}
+	return current.Output
+}
+
+// DefaultPath ~/.met/config.yaml
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, ".met", "config.yaml")
+}
+
+// Load 读取配置文件，required为false时文件不存在返回空配置
+func Load(path string, required bool) (*File, error) {
+	data, err := os.ReadFile(utils.ExpandHome(path))
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %v error: %w", path, err)
+	}
+
+	var file File
+	err = yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %v error: %w", path, err)
+	}
+	return &file, nil
+}
+
+// ProfileNames 排序后的profile名称
+func (f *File) ProfileNames() []string {
+	var names []string
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromEnv 读取MET_DB MET_NETWORK MET_ACCOUNT MET_LOGLEVEL MET_OUTPUT
+func FromEnv() Settings {
+	settings := Settings{
+		Db:       os.Getenv(EnvPrefix + "DB"),
+		Network:  os.Getenv(EnvPrefix + "NETWORK"),
+		Account:  os.Getenv(EnvPrefix + "ACCOUNT"),
+		LogLevel: os.Getenv(EnvPrefix + "LOGLEVEL"),
+		Output:   os.Getenv(EnvPrefix + "OUTPUT"),
+	}
+	if settings.Db == "" {
+		settings.Db = os.Getenv(LegacyDbEnv)
+	}
+	return settings
+}
+
+// Merge 用override中不为空的字段覆盖s
+func (s Settings) Merge(override Settings) Settings {
+	if override.Db != "" {
+		s.Db = override.Db
+	}
+	if override.Network != "" {
+		s.Network = override.Network
+	}
+	if override.Account != "" {
+		s.Account = override.Account
+	}
+	if override.LogLevel != "" {
+		s.LogLevel = override.LogLevel
+	}
+	if override.Output != "" {
+		s.Output = override.Output
+	}
+	return s
+}
+
+// Resolve 按 flags > 环境变量 > profile > 配置文件顶层 合并设置，未设置的字段由使用者取默认值
+// profile为空时依次使用MET_PROFILE和配置文件中的profile，指定的profile不存在时返回错误
+func Resolve(file *File, profile string, env, flags Settings) (Settings, string, error) {
+	if profile == "" {
+		profile = os.Getenv(EnvPrefix + "PROFILE")
+	}
+	if profile == "" {
+		profile = file.Profile
+	}
+
+	settings := file.Settings
+	if profile != "" {
+		p, ok := file.Profiles[profile]
+		if !ok {
+			return Settings{}, "", fmt.Errorf("profile: %v not exist, available: %v", profile, file.ProfileNames())
+		}
+		settings = settings.Merge(p)
+	}
+
+	settings = settings.Merge(env).Merge(flags)
+	return settings, profile, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/config -run 'TestResolve'
+func TestResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+profile: work
+loglevel: warn
+output: table
+profiles:
+  work:
+    db: /tmp/work.db
+    network: mainnet
+    account: deployer
+  personal:
+    db: /tmp/personal.db
+    loglevel: debug
+`), 0600)
+	assert.NoError(t, err)
+
+	file, err := Load(path, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"personal", "work"}, file.ProfileNames())
+
+	t.Setenv("MET_PROFILE", "")
+
+	// 配置文件中的默认profile，顶层设置对profile生效
+	settings, profile, err := Resolve(file, "", Settings{}, Settings{})
+	assert.NoError(t, err)
+	assert.Equal(t, "work", profile)
+	assert.Equal(t, Settings{Db: "/tmp/work.db", Network: "mainnet", Account: "deployer", LogLevel: "warn", Output: "table"}, settings)
+
+	// flags > 环境变量 > profile
+	settings, profile, err = Resolve(file, "personal", Settings{Db: "/tmp/env.db", LogLevel: "info"}, Settings{LogLevel: "error"})
+	assert.NoError(t, err)
+	assert.Equal(t, "personal", profile)
+	assert.Equal(t, Settings{Db: "/tmp/env.db", LogLevel: "error", Output: "table"}, settings)
+
+	t.Setenv("MET_PROFILE", "personal")
+	_, profile, err = Resolve(file, "", Settings{}, Settings{})
+	assert.NoError(t, err)
+	assert.Equal(t, "personal", profile)
+
+	_, _, err = Resolve(file, "unknown", Settings{}, Settings{})
+	assert.Error(t, err)
+
+	// 没有配置文件
+	file, err = Load(filepath.Join(t.TempDir(), "none.yaml"), false)
+	assert.NoError(t, err)
+	assert.Empty(t, file.Profiles)
+	_, err = Load(filepath.Join(t.TempDir(), "none.yaml"), true)
+	assert.Error(t, err)
+}
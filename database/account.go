@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"met/utils"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,14 +17,24 @@ type Account struct {
 
 	// Value 字段是否已经加密
 	Encrypted bool
+	// 最近一次lock/unlock时所用密码的摘要，用于在未加密状态下校验密码(例如 --override-policy)
+	PasswordHash string
 
 	PathFormat string
 	Passphrase string
 
+	// 标签，逗号分隔
+	Tags string
+	// 分组，逗号分隔，配合HD index范围使用，eg: group:airdrop[0..49]
+	Groups string
+
 	// 是否是当前账号
 	Current bool
 	// 当Current为true 并且 Type 是MnemonicType时，所对应的助记词的index
 	CurrentIndex uint
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 func (account Account) SwitchTo(newIndex uint) Account {
@@ -148,6 +159,15 @@ func CurrentAccount() (account Account, err error) {
 	return
 }
 
+var (
+	defaultAccount string
+)
+
+// SetDefaultAccount 设置配置文件profile或者MET_ACCOUNT中的account，QueryAccountOrCurrent没有指定name时优先使用
+func SetDefaultAccount(name string) {
+	defaultAccount = name
+}
+
 func QueryAccountOrCurrent(name string, index uint) (*Account, error) {
 	var (
 		acc    Account
@@ -155,6 +175,10 @@ func QueryAccountOrCurrent(name string, index uint) (*Account, error) {
 		logger = utils.GetLogger("QueryAccountOrCurrent")
 	)
 
+	if name == "" {
+		name = defaultAccount
+	}
+
 	if name != "" {
 		logger.Info().Msgf("Query account: %v", name)
 		acc, err = QueryAccount(name)
@@ -172,6 +196,7 @@ func QueryAccountOrCurrent(name string, index uint) (*Account, error) {
 }
 
 // 那么为空时表示所有
+// 每个账户有自己的密码: 已经设置过密码的账户只能用它自己的密码lock，使用 ChangeAccountPassword 修改密码
 func LockAccount(name string, password string) error {
 	var (
 		accountList []Account
@@ -185,21 +210,29 @@ func LockAccount(name string, password string) error {
 		return fmt.Errorf("query account by name: %s error: %w", name, err)
 	}
 
+	// 先检查所有账户的密码，避免只lock了一部分
 	for _, acc := range accountList {
-		if acc.Encrypted {
-			logger.Info().Msgf("account: %v already locked,skip", acc.Name)
-			continue
-		}
-		// encrypt
-		logger.Info().Msgf("lock account: %v", acc.Name)
-		encrypted := utils.Encrypt(password, acc.Value)
-		err = Conn.WithContext(ctx).Model(&Account{}).Where(&Account{Name: acc.Name}).Updates(map[string]any{"encrypted": true, "value": encrypted}).Error
-		if err != nil {
-			return fmt.Errorf("lock account: %v error: %w", acc.Name, err)
+		if !acc.Encrypted && acc.PasswordHash != "" && !utils.VerifyPassword(password, acc.PasswordHash) {
+			return fmt.Errorf("password of account: %v mismatch, use 'account passwd' to change it", acc.Name)
 		}
 	}
 
-	return nil
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, acc := range accountList {
+			if acc.Encrypted {
+				logger.Info().Msgf("account: %v already locked,skip", acc.Name)
+				continue
+			}
+			// encrypt
+			logger.Info().Msgf("lock account: %v", acc.Name)
+			encrypted := utils.Encrypt(password, acc.Value)
+			err := tx.Model(&Account{}).Where(&Account{Name: acc.Name}).Updates(map[string]any{"encrypted": true, "value": encrypted, "password_hash": utils.HashPassword(password)}).Error
+			if err != nil {
+				return fmt.Errorf("lock account: %v error: %w", acc.Name, err)
+			}
+		}
+		return nil
+	})
 }
 
 func UnlockAccount(name string, password string) error {
@@ -223,9 +256,9 @@ func UnlockAccount(name string, password string) error {
 		logger.Info().Msgf("unlock account: %v", acc.Name)
 		decrypted := utils.Decrypt(password, acc.Value)
 		if decrypted == "" {
-			return fmt.Errorf("wrong password")
+			return fmt.Errorf("wrong password of account: %v", acc.Name)
 		}
-		err = Conn.Model(&Account{}).Where(&Account{Name: acc.Name}).Updates(map[string]any{"encrypted": false, "value": decrypted}).Error
+		err = Conn.Model(&Account{}).Where(&Account{Name: acc.Name}).Updates(map[string]any{"encrypted": false, "value": decrypted, "password_hash": utils.HashPassword(password)}).Error
 		if err != nil {
 			return fmt.Errorf("lock account: %v error: %w", acc.Name, err)
 		}
@@ -233,3 +266,96 @@ func UnlockAccount(name string, password string) error {
 
 	return nil
 }
+
+// ChangeAccountPassword 修改账户密码，已lock的账户用新密码重新加密，在一个事务中完成
+func ChangeAccountPassword(name string, oldPassword string, newPassword string) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	logger := utils.GetLogger("ChangeAccountPassword")
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var acc Account
+		err := tx.Model(&Account{}).First(&acc, "name = ?", name).Error
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("account: %s not exist", name)
+		} else if err != nil {
+			return err
+		}
+
+		updates := map[string]any{"password_hash": utils.HashPassword(newPassword)}
+		if acc.Encrypted {
+			decrypted := utils.Decrypt(oldPassword, acc.Value)
+			if decrypted == "" {
+				return fmt.Errorf("wrong password")
+			}
+			logger.Info().Msgf("re-encrypt account: %v", name)
+			updates["value"] = utils.Encrypt(newPassword, decrypted)
+		} else if acc.PasswordHash != "" && !utils.VerifyPassword(oldPassword, acc.PasswordHash) {
+			return fmt.Errorf("wrong password")
+		}
+
+		return tx.Model(&Account{}).Where("name = ?", name).Updates(updates).Error
+	})
+}
+
+// UpdateAccount 修改账户信息，newName不为空时重命名账户(同时修改策略、交易记录和批量交易等关联数据)
+// updates 的key为数据库字段名
+func UpdateAccount(name string, newName string, updates map[string]any) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	logger := utils.GetLogger("UpdateAccount")
+
+	_, err := QueryAccount(name)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("account: %s not exist", name)
+	} else if err != nil {
+		return err
+	}
+
+	if newName != "" && newName != name {
+		_, err = QueryAccount(newName)
+		if err == nil {
+			return fmt.Errorf("account: %s already exists", newName)
+		} else if err != gorm.ErrRecordNotFound {
+			return err
+		}
+	}
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			logger.Info().Msgf("update account: %v fields: %v", name, len(updates))
+			err := tx.Model(&Account{}).Where("name = ?", name).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if newName == "" || newName == name {
+			return nil
+		}
+
+		logger.Info().Msgf("rename account: %v to %v", name, newName)
+		err := tx.Model(&Account{}).Where("name = ?", name).Update("name", newName).Error
+		if err != nil {
+			return err
+		}
+		// 签名审计日志的账户名参与hash计算，不修改，追加rename记录，按账户名查询时包含旧账户名的记录
+		err = addRenameLog(tx, name, newName)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Policy{}).Where("account_name = ?", name).Update("account_name", newName).Error
+		if err != nil {
+			return err
+		}
+		for _, model := range []any{&Spend{}, &Transaction{}, &Batch{}} {
+			err = tx.Model(model).Where("account_name = ?", name).Update("account_name", newName).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
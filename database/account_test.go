@@ -0,0 +1,60 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/database -run 'TestAccountPassword'
+func TestAccountPassword(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	for _, name := range []string{"a", "b"} {
+		err := AddAccount(&Account{Name: name, Type: "private key", Value: "0x01"})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, LockAccount("a", "pa"))
+	assert.NoError(t, UnlockAccount("a", "pa"))
+
+	// a 已经有自己的密码，批量lock时密码不匹配
+	assert.Error(t, LockAccount("", "pb"))
+	b, _ := QueryAccount("b")
+	assert.False(t, b.Encrypted)
+
+	assert.NoError(t, LockAccount("a", "pa"))
+	assert.Error(t, ChangeAccountPassword("a", "wrong", "pa2"))
+	assert.NoError(t, ChangeAccountPassword("a", "pa", "pa2"))
+	assert.Error(t, UnlockAccount("a", "pa"))
+	assert.NoError(t, UnlockAccount("a", "pa2"))
+
+	a, _ := QueryAccount("a")
+	assert.Equal(t, "0x01", a.Value)
+
+	assert.NoError(t, UpdateAccount("a", "c", map[string]any{"tags": "bots"}))
+	c, err := QueryAccount("c")
+	assert.NoError(t, err)
+	assert.Equal(t, "bots", c.Tags)
+	assert.Error(t, UpdateAccount("c", "b", nil))
+}
+
+// go test -count=1 -v met/database -run 'TestRenameAccount'
+func TestRenameAccount(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	assert.NoError(t, AddAccount(&Account{Name: "a", Type: "private key", Value: "0x01"}))
+	assert.NoError(t, AddTransaction(&Transaction{Hash: "0x01", Network: "eth", AccountName: "a"}))
+	assert.NoError(t, AddBatch(&Batch{Network: "eth", File: "a.csv", AccountName: "a"}, nil))
+
+	assert.NoError(t, UpdateAccount("a", "b", nil))
+
+	txs, err := QueryTransactions(TransactionFilter{AccountName: "b"})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+
+	batch, err := QueryLatestBatch("eth", "", "a.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", batch.AccountName)
+}
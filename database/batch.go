@@ -0,0 +1,127 @@
+package database
+
+import (
+	"met/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BatchTableName     = "batches"
+	BatchItemTableName = "batch_items"
+)
+
+const (
+	// 还没有签名
+	BatchItemPending = "pending"
+	// 已签名并保存，可能还没有广播，恢复时重新广播相同的交易
+	BatchItemSigned = "signed"
+	// 已广播
+	BatchItemSent = "sent"
+	// 上链并且执行成功
+	BatchItemMined = "mined"
+	// 上链但是执行失败
+	BatchItemFailed = "failed"
+	// nonce被其他交易使用，不会重新发送
+	BatchItemReplaced = "replaced"
+)
+
+// Batch tx batch的一次执行，同一个账户在同一个网络上用同一个文件执行时从中断处恢复
+type Batch struct {
+	ID           uint   `gorm:"primaryKey"`
+	Network      string `gorm:"index:idx_batch"`
+	From         string `gorm:"index:idx_batch"`
+	File         string `gorm:"index:idx_batch"`
+	FileHash     string
+	AccountName  string
+	AccountIndex uint
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (Batch) TableName() string {
+	return BatchTableName
+}
+
+// BatchItem csv中的一行，value和amount为文件中人类可读的数量
+type BatchItem struct {
+	ID      uint `gorm:"primaryKey"`
+	BatchID uint `gorm:"uniqueIndex:idx_batch_item"`
+	// csv中的行号
+	Row    int `gorm:"uniqueIndex:idx_batch_item"`
+	To     string
+	Value  string
+	Data   string
+	Token  string
+	Amount string
+
+	Nonce uint64
+	Hash  string
+	// 签名后的交易，0x开头的hex
+	Raw    string
+	Status string
+	Error  string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (BatchItem) TableName() string {
+	return BatchItemTableName
+}
+
+// op
+
+// AddBatch 保存batch和所有行
+func AddBatch(batch *Batch, items []BatchItem) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(batch).Error
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].BatchID = batch.ID
+			if items[i].Status == "" {
+				items[i].Status = BatchItemPending
+			}
+		}
+		return tx.CreateInBatches(items, 100).Error
+	})
+}
+
+// QueryLatestBatch 没有时返回gorm.ErrRecordNotFound
+func QueryLatestBatch(network, from, file string) (*Batch, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var batch Batch
+	err := Conn.WithContext(ctx).Where("network = ? AND `from` = ? AND file = ?", network, from, file).Order("id desc").First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// QueryBatchItems 按行号排序
+func QueryBatchItems(batchID uint) ([]BatchItem, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var items []BatchItem
+	err := Conn.WithContext(ctx).Where("batch_id = ?", batchID).Order("row").Find(&items).Error
+	return items, err
+}
+
+// UpdateBatchItem 更新发送进度
+func UpdateBatchItem(item *BatchItem) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Model(item).
+		Select("nonce", "hash", "raw", "status", "error", "updated_at").
+		Updates(item).Error
+}
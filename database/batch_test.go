@@ -0,0 +1,42 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// go test -count=1 -v met/database -run 'TestBatch'
+func TestBatch(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	const from = "0x41BB7A889F20b71E6AaBa492298041E84691C41B"
+
+	_, err := QueryLatestBatch("eth", from, "/tmp/pay.csv")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	items := []BatchItem{{Row: 2, To: from, Value: "1"}, {Row: 3, To: from, Value: "2"}}
+	batch := &Batch{Network: "eth", From: from, File: "/tmp/pay.csv", FileHash: "h1"}
+	assert.NoError(t, AddBatch(batch, items))
+	assert.NoError(t, AddBatch(&Batch{Network: "eth", From: from, File: "/tmp/pay.csv", FileHash: "h2"}, []BatchItem{{Row: 2}}))
+
+	latest, err := QueryLatestBatch("eth", from, "/tmp/pay.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "h2", latest.FileHash)
+
+	items[1].Nonce = 5
+	items[1].Hash = "0x01"
+	items[1].Status = BatchItemSent
+	assert.NoError(t, UpdateBatchItem(&items[1]))
+
+	saved, err := QueryBatchItems(batch.ID)
+	assert.NoError(t, err)
+	assert.Len(t, saved, 2)
+	assert.Equal(t, BatchItemPending, saved[0].Status)
+	assert.Equal(t, BatchItemSent, saved[1].Status)
+	assert.Equal(t, uint64(5), saved[1].Nonce)
+	assert.Equal(t, "0x01", saved[1].Hash)
+}
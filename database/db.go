@@ -1,8 +1,10 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -10,11 +12,29 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+const (
+	// 多个met进程同时写数据库时等待锁的时间，单位ms
+	busyTimeout = 5000
+)
+
 var (
 	Conn *gorm.DB
+	// 数据库文件路径，用于备份
+	dbPath string
 )
 
+// InitDB 打开数据库并执行未执行的migration，执行前备份已有的数据库
 func InitDB(level string, dsn string) {
+	OpenDB(level, dsn)
+
+	_, _, err := Migrate(true)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// OpenDB 打开数据库，只创建schema_version表，不执行migration
+func OpenDB(level string, dsn string) {
 	var err error
 
 	newLogger := logger.New(
@@ -26,15 +46,31 @@ func InitDB(level string, dsn string) {
 			Colorful:                  true,               // 彩色打印
 		},
 	)
-	Conn, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
+	Conn, err = gorm.Open(sqlite.Open(withBusyTimeout(dsn)), &gorm.Config{
 		Logger: newLogger,
 	})
 	if err != nil {
 		panic(err)
 	}
+	dbPath = dsn
 
-	Conn.AutoMigrate(&Account{})
-	Conn.AutoMigrate(&Network{})
+	// 多个met进程同时创建数据库时，AutoMigrate检查表不存在之后可能已经被其他进程创建
+	err = Conn.Exec("CREATE TABLE IF NOT EXISTS `schema_version` (`version` integer,`name` text,`applied_at` datetime,PRIMARY KEY (`version`))").Error
+	if err != nil {
+		panic(err)
+	}
+}
+
+// withBusyTimeout 给dsn添加_busy_timeout参数，数据库被其他进程锁定时等待而不是直接返回database is locked
+func withBusyTimeout(dsn string) string {
+	if strings.Contains(dsn, "_busy_timeout") {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%s_busy_timeout=%d", dsn, sep, busyTimeout)
 }
 
 func ormLogLevel(levelString string) logger.LogLevel {
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"fmt"
+	utils "met/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SchemaVersionTableName = "schema_version"
+)
+
+// SchemaVersion 每个已执行的migration一行
+type SchemaVersion struct {
+	Version   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (SchemaVersion) TableName() string {
+	return SchemaVersionTableName
+}
+
+// Migration 只支持升级，Up在事务中执行，Version从1开始连续递增
+// 新增表或者字段时在migrations末尾追加，不要修改已经发布的migration
+// Up中不要使用会继续修改的model，使用migration_schema.go中对应版本的结构体
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up: func(tx *gorm.DB) error {
+			// 没有schema_version的旧版本数据库已经有这些表，AutoMigrate只补充缺少的字段
+			return tx.AutoMigrate(&accountV1{}, &networkV1{}, &policyV1{}, &spendV1{}, &signingLogV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "fill network rpcs from rpc",
+		Up: func(tx *gorm.DB) error {
+			var networks []networkV2
+			err := tx.Find(&networks).Error
+			if err != nil {
+				return err
+			}
+			for _, network := range networks {
+				if len(network.Rpcs) > 0 || network.Rpc == "" {
+					continue
+				}
+				network.Rpcs = []networkRpcV2{{Url: network.Rpc, Weight: 1}}
+				err = tx.Model(&networkV2{}).Where("name = ?", network.Name).Select("rpcs").Updates(&network).Error
+				if err != nil {
+					return fmt.Errorf("network: %v %w", network.Name, err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 3,
+		Name:    "add account and network timestamps",
+		Up: func(tx *gorm.DB) error {
+			err := tx.AutoMigrate(&accountV3{}, &networkV3{})
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			for _, table := range []string{"accounts", "networks"} {
+				err = tx.Table(table).Where("created_at IS NULL").Updates(map[string]any{"created_at": now, "updated_at": now}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add nonce manager tables",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&nonceV4{}, &nonceAllocationV4{})
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add transactions table",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&transactionV5{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add batch tables",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&batchV6{}, &batchItemV6{})
+		},
+	},
+}
+
+// MigrationStatus AppliedAt为nil表示未执行
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+}
+
+// LatestSchemaVersion 当前程序支持的最新schema版本
+func LatestSchemaVersion() uint {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaStatus 返回数据库当前的schema版本和每个migration的状态
+func SchemaStatus() (uint, []MigrationStatus, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var applied []SchemaVersion
+	err := Conn.WithContext(ctx).Order("version").Find(&applied).Error
+	if err != nil {
+		return 0, nil, err
+	}
+
+	appliedAt := make(map[uint]time.Time)
+	var current uint
+	for _, v := range applied {
+		appliedAt[v.Version] = v.AppliedAt
+		current = max(current, v.Version)
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if t, ok := appliedAt[m.Version]; ok {
+			status.AppliedAt = &t
+		}
+		statuses = append(statuses, status)
+	}
+	return current, statuses, nil
+}
+
+// Migrate 按顺序执行未执行的migration，返回执行的migration
+// backup为true并且数据库中已经有数据时，先备份到数据库文件所在目录
+func Migrate(backup bool) (backupPath string, applied []Migration, err error) {
+	logger := utils.GetLogger("Migrate")
+
+	current, _, err := SchemaStatus()
+	if err != nil {
+		return "", nil, err
+	}
+	if current > LatestSchemaVersion() {
+		return "", nil, fmt.Errorf("database schema version: %v is newer than supported: %v, please upgrade met", current, LatestSchemaVersion())
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return "", nil, nil
+	}
+
+	if backup && hasData() {
+		backupPath, err = BackupDB(fmt.Sprintf("v%d", current))
+		if err != nil {
+			return "", nil, err
+		}
+		logger.Info().Msgf("database backup: %v", backupPath)
+	}
+
+	for _, m := range pending {
+		var done bool
+		err = Conn.Transaction(func(tx *gorm.DB) error {
+			// 多个met进程同时执行migration时，先获取写锁再检查是否已经被其他进程执行
+			err := lockSchemaVersion(tx)
+			if err != nil {
+				return err
+			}
+			var count int64
+			err = tx.Model(&SchemaVersion{}).Where("version = ?", m.Version).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				done = true
+				return nil
+			}
+
+			logger.Info().Msgf("migrate database to version: %v (%v)", m.Version, m.Name)
+			err = m.Up(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return backupPath, applied, fmt.Errorf("migrate to version: %v (%v) error: %w", m.Version, m.Name, err)
+		}
+		if done {
+			logger.Debug().Msgf("version: %v (%v) already migrated", m.Version, m.Name)
+			continue
+		}
+		applied = append(applied, m)
+	}
+
+	return backupPath, applied, nil
+}
+
+// lockSchemaVersion 事务中的第一条语句是写语句时，sqlite直接获取写锁(与BEGIN IMMEDIATE相同)
+// 其他进程在这里等待_busy_timeout，而不是在读取之后升级锁时返回database is locked
+func lockSchemaVersion(tx *gorm.DB) error {
+	return tx.Exec("UPDATE schema_version SET version = version WHERE 1 = 0").Error
+}
+
+// BackupDB 使用VACUUM INTO把数据库备份到同一目录，eg: met.db.20240101T120000.v2.bak
+func BackupDB(tag string) (string, error) {
+	if dbPath == "" || strings.HasPrefix(dbPath, "file:") || dbPath == ":memory:" {
+		return "", fmt.Errorf("cannot backup database: %v", dbPath)
+	}
+
+	name := fmt.Sprintf("%s.%s.%s", filepath.Base(dbPath), time.Now().Format("20060102T150405"), tag)
+	backupPath := filepath.Join(filepath.Dir(dbPath), name+".bak")
+	// 同一秒内多次备份
+	for i := 1; fileExists(backupPath); i++ {
+		backupPath = filepath.Join(filepath.Dir(dbPath), fmt.Sprintf("%s.%d.bak", name, i))
+	}
+	err := Conn.Exec("VACUUM INTO ?", backupPath).Error
+	if err != nil {
+		return "", fmt.Errorf("backup database to: %v error: %w", backupPath, err)
+	}
+	return backupPath, nil
+}
+
+// hasData 已有accounts或者networks表，说明不是新建的数据库
+func hasData() bool {
+	migrator := Conn.Migrator()
+	return migrator.HasTable(AccountTableName) || migrator.HasTable(NetworkTableName)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,223 @@
+package database
+
+import "time"
+
+// 已发布的migration使用的表结构，与发布时的model一致
+// 之后修改model时不要修改这里，新增字段时在新的migration中定义新的结构体
+
+// v1
+
+type accountV1 struct {
+	Name         string `gorm:"unique;"`
+	Type         string
+	Value        string
+	Encrypted    bool
+	PasswordHash string
+	PathFormat   string
+	Passphrase   string
+	Tags         string
+	Groups       string
+	Current      bool
+	CurrentIndex uint
+}
+
+func (accountV1) TableName() string {
+	return "accounts"
+}
+
+type networkV1 struct {
+	Name string `gorm:"unique;"`
+	Rpc  string
+	// json
+	Rpcs      string
+	Transport string
+	Symbol    string
+	Explorer  string
+	Defaults  string
+	ChainId   uint64
+	Current   bool
+}
+
+func (networkV1) TableName() string {
+	return "networks"
+}
+
+type policyV1 struct {
+	AccountName      string `gorm:"unique;"`
+	MaxValue         string
+	DailyCap         string
+	AllowedTo        string
+	AllowedSelectors string
+	MaxFeePerGas     string
+}
+
+func (policyV1) TableName() string {
+	return "policies"
+}
+
+type spendV1 struct {
+	AccountName string `gorm:"index"`
+	Network     string `gorm:"index"`
+	Value       string
+	TxHash      string
+	CreatedAt   time.Time
+}
+
+func (spendV1) TableName() string {
+	return "spends"
+}
+
+type signingLogV1 struct {
+	ID           uint `gorm:"primaryKey"`
+	Timestamp    int64
+	Kind         string
+	AccountName  string `gorm:"index"`
+	AccountIndex uint
+	Network      string `gorm:"index"`
+	ChainId      string
+	Summary      string
+	TxHash       string
+	Outcome      string
+	PrevHash     string
+	Hash         string
+}
+
+func (signingLogV1) TableName() string {
+	return "signing_log"
+}
+
+// v2
+
+type networkRpcV2 struct {
+	Url    string
+	Weight uint
+}
+
+type networkV2 struct {
+	Name string
+	Rpc  string
+	Rpcs []networkRpcV2 `gorm:"serializer:json"`
+}
+
+func (networkV2) TableName() string {
+	return "networks"
+}
+
+// v3
+
+type accountV3 struct {
+	accountV1
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (accountV3) TableName() string {
+	return "accounts"
+}
+
+type networkV3 struct {
+	networkV1
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (networkV3) TableName() string {
+	return "networks"
+}
+
+// v4
+
+type nonceV4 struct {
+	Network   string `gorm:"primaryKey"`
+	Address   string `gorm:"primaryKey"`
+	Next      uint64
+	UpdatedAt time.Time
+}
+
+func (nonceV4) TableName() string {
+	return "nonces"
+}
+
+type nonceAllocationV4 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Network   string `gorm:"uniqueIndex:idx_nonce_allocation"`
+	Address   string `gorm:"uniqueIndex:idx_nonce_allocation"`
+	Nonce     uint64 `gorm:"uniqueIndex:idx_nonce_allocation"`
+	Status    string
+	TxHash    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (nonceAllocationV4) TableName() string {
+	return "nonce_allocations"
+}
+
+// v5
+
+type transactionV5 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Hash         string `gorm:"uniqueIndex"`
+	Network      string `gorm:"index"`
+	ChainId      string
+	AccountName  string `gorm:"index"`
+	AccountIndex uint
+	From         string `gorm:"index"`
+	To           string
+	Nonce        uint64
+	Value        string
+	Type         uint8
+	Summary      string
+	Raw          string
+	Status       string `gorm:"index"`
+	BlockNumber  uint64
+	GasUsed      uint64
+	ReplacedBy   string
+	CreatedAt    time.Time `gorm:"index"`
+	UpdatedAt    time.Time
+}
+
+func (transactionV5) TableName() string {
+	return "transactions"
+}
+
+// v6
+
+type batchV6 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Network      string `gorm:"index:idx_batch"`
+	From         string `gorm:"index:idx_batch"`
+	File         string `gorm:"index:idx_batch"`
+	FileHash     string
+	AccountName  string
+	AccountIndex uint
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (batchV6) TableName() string {
+	return "batches"
+}
+
+type batchItemV6 struct {
+	ID      uint `gorm:"primaryKey"`
+	BatchID uint `gorm:"uniqueIndex:idx_batch_item"`
+	Row     int  `gorm:"uniqueIndex:idx_batch_item"`
+	To      string
+	Value   string
+	Data    string
+	Token   string
+	Amount  string
+	Nonce   uint64
+	Hash    string
+	Raw     string
+	Status  string
+	Error   string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (batchItemV6) TableName() string {
+	return "batch_items"
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// go test -count=1 -v met/database -run 'TestMigrate'
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	OpenDB("silent", filepath.Join(dir, "met.db"))
+
+	// 没有schema_version的旧版本数据库
+	err := Conn.Exec("CREATE TABLE `networks` (`name` text,`rpc` text,`symbol` text,`explorer` text,`current` numeric,CONSTRAINT `uni_networks_name` UNIQUE (`name`))").Error
+	assert.NoError(t, err)
+	err = Conn.Exec("INSERT INTO networks (name, rpc, symbol, current) VALUES ('eth', 'http://127.0.0.1:8545', 'ETH', true)").Error
+	assert.NoError(t, err)
+
+	current, statuses, err := SchemaStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), current)
+	assert.Len(t, statuses, int(LatestSchemaVersion()))
+	assert.Nil(t, statuses[0].AppliedAt)
+
+	backupPath, applied, err := Migrate(true)
+	assert.NoError(t, err)
+	assert.Len(t, applied, int(LatestSchemaVersion()))
+	_, err = os.Stat(backupPath)
+	assert.NoError(t, err)
+
+	network, err := QueryNetwork("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, []NetworkRpc{{Url: "http://127.0.0.1:8545", Weight: 1}}, network.Rpcs)
+	assert.False(t, network.CreatedAt.IsZero())
+
+	current, statuses, err = SchemaStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), current)
+	assert.NotNil(t, statuses[0].AppliedAt)
+
+	// 已经是最新版本
+	backupPath, applied, err = Migrate(true)
+	assert.NoError(t, err)
+	assert.Empty(t, backupPath)
+	assert.Empty(t, applied)
+}
+
+// go test -count=1 -v met/database -run 'TestMigrateConcurrent'
+func TestMigrateConcurrent(t *testing.T) {
+	OpenDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	// 每个goroutine使用连接池中不同的连接，与多个met进程同时启动相同
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	applied := make([][]Migration, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, applied[i], errs[i] = Migrate(false)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range errs {
+		assert.NoError(t, errs[i])
+		total += len(applied[i])
+	}
+	// 每个migration只执行一次
+	assert.Equal(t, int(LatestSchemaVersion()), total)
+
+	current, _, err := SchemaStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), current)
+}
+
+// go test -count=1 -v met/database -run 'TestMigrateFrozenSchema'
+func TestMigrateFrozenSchema(t *testing.T) {
+	OpenDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	// 执行到version 1时还没有version 3添加的时间字段
+	err := Conn.Transaction(func(tx *gorm.DB) error {
+		return migrations[0].Up(tx)
+	})
+	assert.NoError(t, err)
+	assert.False(t, Conn.Migrator().HasColumn(AccountTableName, "created_at"))
+	assert.False(t, Conn.Migrator().HasColumn(NetworkTableName, "created_at"))
+	assert.True(t, Conn.Migrator().HasColumn(NetworkTableName, "chain_id"))
+}
+
+// go test -count=1 -v met/database -run 'TestMigrateModels'
+func TestMigrateModels(t *testing.T) {
+	OpenDB("silent", filepath.Join(t.TempDir(), "met.db"))
+	_, _, err := Migrate(false)
+	assert.NoError(t, err)
+
+	// model新增字段时需要添加migration
+	models := []any{&Account{}, &Network{}, &Policy{}, &Spend{}, &SigningLog{}, &Nonce{}, &NonceAllocation{}, &Transaction{}, &Batch{}, &BatchItem{}}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: Conn}
+		assert.NoError(t, stmt.Parse(model))
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, Conn.Migrator().HasColumn(model, field.DBName), "%v.%v", stmt.Schema.Table, field.DBName)
+		}
+	}
+}
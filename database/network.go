@@ -3,29 +3,77 @@ package database
 import (
 	"fmt"
 	utils "met/utils"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type Network struct {
 	Name string `gorm:"unique;"`
-	Rpc  string
+	// 第一个rpc
+	Rpc string
+	// 有序的rpc列表，为空时只使用Rpc(旧版本添加的network)
+	Rpcs []NetworkRpc `gorm:"serializer:json"`
+	// header、认证、TLS和代理设置
+	Transport utils.TransportOptions `gorm:"serializer:json"`
 	// native token symbol, eg: ETH BNB
 	Symbol string
 
 	Explorer string
 
+	// 命令行没有指定flag时使用的默认值
+	Defaults NetworkDefaults `gorm:"serializer:json"`
+
+	// network add时从rpc获取，签名前与rpc返回的eth_chainId比较，0表示未知(旧版本添加的network)
+	ChainId uint64
+
 	Current bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type NetworkRpc struct {
+	Url string
+	// 权重越大越容易被选为主rpc，0表示只作为备用
+	Weight uint
 }
 
 const (
 	NetworkTableName = "networks"
 )
 
+var (
+	defaultNetwork string
+)
+
 func (Network) TableName() string {
 	return NetworkTableName
 }
 
+// String 用于日志，隐藏rpc中的密钥
+func (network Network) String() string {
+	var rpcs []string
+	for _, endpoint := range network.Endpoints() {
+		rpcs = append(rpcs, utils.DisplayRpcUrl(endpoint.Url))
+	}
+	return fmt.Sprintf("{Name: %v ChainId: %v Rpcs: %v Symbol: %v Explorer: %v Current: %v}", network.Name, network.ChainId, strings.Join(rpcs, ","), network.Symbol, network.Explorer, network.Current)
+}
+
+// Endpoints 返回用于utils.DialRpcs的rpc列表
+func (network Network) Endpoints() []utils.RpcEndpoint {
+	if len(network.Rpcs) == 0 {
+		return []utils.RpcEndpoint{{Url: network.Rpc, Weight: 1}}
+	}
+
+	endpoints := make([]utils.RpcEndpoint, 0, len(network.Rpcs))
+	for _, rpc := range network.Rpcs {
+		endpoints = append(endpoints, utils.RpcEndpoint{Url: rpc.Url, Weight: rpc.Weight})
+	}
+	return endpoints
+}
+
 // op
 func QueryNetwork(name string) (network Network, err error) {
 	ctx, cancel := utils.DefaultTimeoutContext()
@@ -88,6 +136,90 @@ func AddNetwork(network *Network) error {
 
 }
 
+// UpdateNetworkRpcs 替换network的rpc列表，Rpc更新为第一个rpc
+func UpdateNetworkRpcs(name string, rpcs []NetworkRpc) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	if len(rpcs) == 0 {
+		return fmt.Errorf("no rpc")
+	}
+
+	network, err := QueryNetwork(name)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("network: %s not exist", name)
+	} else if err != nil {
+		return err
+	}
+
+	network.Rpc = rpcs[0].Url
+	network.Rpcs = rpcs
+	return Conn.WithContext(ctx).Model(&Network{}).Where("name = ?", name).Select("rpc", "rpcs").Updates(&network).Error
+}
+
+// UpdateNetworkTransport 替换network的传输设置
+func UpdateNetworkTransport(name string, transport utils.TransportOptions) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	_, err := QueryNetwork(name)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("network: %s not exist", name)
+	} else if err != nil {
+		return err
+	}
+
+	network := Network{Transport: transport}
+	return Conn.WithContext(ctx).Model(&Network{}).Where("name = ?", name).Select("transport").Updates(&network).Error
+}
+
+// UpdateNetworkChainId 保存network的chainId
+func UpdateNetworkChainId(name string, chainId uint64) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Model(&Network{}).Where("name = ?", name).Update("chain_id", chainId).Error
+}
+
+// ImportNetworks 批量添加network，已经存在的network跳过，返回跳过的network名称
+// 没有当前network时，切换到第一个添加的network
+func ImportNetworks(networks []Network) (skipped []string, err error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	logger := utils.GetLogger("ImportNetworks")
+
+	err = Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current int64
+		err := tx.Model(&Network{}).Where("current = true").Count(&current).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range networks {
+			var count int64
+			err := tx.Model(&Network{}).Where("name = ?", networks[i].Name).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				skipped = append(skipped, networks[i].Name)
+				continue
+			}
+
+			networks[i].Current = current == 0
+			current = 1
+			err = tx.Create(&networks[i]).Error
+			if err != nil {
+				return err
+			}
+			logger.Info().Msgf("network: %v added", networks[i].Name)
+		}
+		return nil
+	})
+	return
+}
+
 func QueryAllNetworks() (networks []Network, err error) {
 	ctx, cancel := utils.DefaultTimeoutContext()
 	defer cancel()
@@ -139,6 +271,11 @@ func CurrentNetwork() (network Network, err error) {
 	return
 }
 
+// SetDefaultNetwork 设置配置文件profile或者MET_NETWORK中的network，QueryNetworkOrCurrent没有指定name时优先使用
+func SetDefaultNetwork(name string) {
+	defaultNetwork = name
+}
+
 func QueryNetworkOrCurrent(name string) (*Network, error) {
 	var (
 		net    Network
@@ -146,6 +283,10 @@ func QueryNetworkOrCurrent(name string) (*Network, error) {
 		logger = utils.GetLogger("QueryNetworkOrCurrent")
 	)
 
+	if name == "" {
+		name = defaultNetwork
+	}
+
 	if name != "" {
 		logger.Info().Msgf("Query network: %v", name)
 		net, err = QueryNetwork(name)
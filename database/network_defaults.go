@@ -0,0 +1,117 @@
+package database
+
+import (
+	"fmt"
+	"met/consts"
+	utils "met/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 浏览器链接模板中的占位符
+	ExplorerPlaceholder     = "{explorer}"
+	ExplorerTypePlaceholder = "{type}"
+	ExplorerIdPlaceholder   = "{id}"
+)
+
+// NetworkDefaults 命令行没有指定对应flag时使用的默认值，零值表示未设置
+type NetworkDefaults struct {
+	// auto legacy eip1559
+	GasMode  string `json:"gasMode,omitempty"`
+	GasRatio string `json:"gasRatio,omitempty"`
+	// gasPrice和gasFeeCap的上限，单位gwei
+	MaxFee string `json:"maxFee,omitempty"`
+	// nil表示未设置，0也是有效的值
+	Confirmations *int8 `json:"confirmations,omitempty"`
+	// 单位秒
+	RequestTimeout uint `json:"requestTimeout,omitempty"`
+	ReceiptTimeout uint `json:"receiptTimeout,omitempty"`
+	// 浏览器链接模板，eg: {explorer}/{type}/{id}  https://tronscan.org/#/{type}/{id}
+	// type为tx或address
+	ExplorerUrl string `json:"explorerUrl,omitempty"`
+}
+
+func (d NetworkDefaults) IsZero() bool {
+	return d.GasMode == "" && d.GasRatio == "" && d.MaxFee == "" && d.Confirmations == nil &&
+		d.RequestTimeout == 0 && d.ReceiptTimeout == 0 && d.ExplorerUrl == ""
+}
+
+// Summary 用于显示
+func (d NetworkDefaults) Summary() []string {
+	var lines []string
+	if d.GasMode != "" {
+		lines = append(lines, fmt.Sprintf("Default Gas Mode: %s", d.GasMode))
+	}
+	if d.GasRatio != "" {
+		lines = append(lines, fmt.Sprintf("Default Gas Ratio: %s", d.GasRatio))
+	}
+	if d.MaxFee != "" {
+		lines = append(lines, fmt.Sprintf("Max Fee: %s Gwei", d.MaxFee))
+	}
+	if d.Confirmations != nil {
+		lines = append(lines, fmt.Sprintf("Default Confirmations: %d", *d.Confirmations))
+	}
+	if d.RequestTimeout != 0 {
+		lines = append(lines, fmt.Sprintf("Request Timeout: %ds", d.RequestTimeout))
+	}
+	if d.ReceiptTimeout != 0 {
+		lines = append(lines, fmt.Sprintf("Receipt Timeout: %ds", d.ReceiptTimeout))
+	}
+	if d.ExplorerUrl != "" {
+		lines = append(lines, fmt.Sprintf("Explorer Url: %s", d.ExplorerUrl))
+	}
+	return lines
+}
+
+// RequestTimeout rpc请求的超时时间，默认consts.DefaultTimeout
+func (network Network) RequestTimeout() time.Duration {
+	if network.Defaults.RequestTimeout != 0 {
+		return time.Second * time.Duration(network.Defaults.RequestTimeout)
+	}
+	return time.Second * consts.DefaultTimeout
+}
+
+// ReceiptTimeout 发送交易后等待回执的超时时间，默认consts.DefaultTimeout
+func (network Network) ReceiptTimeout() time.Duration {
+	if network.Defaults.ReceiptTimeout != 0 {
+		return time.Second * time.Duration(network.Defaults.ReceiptTimeout)
+	}
+	return time.Second * consts.DefaultTimeout
+}
+
+// TxLink 交易的浏览器链接
+func (network Network) TxLink(hash string) string {
+	return network.explorerLink("tx", hash)
+}
+
+// AddressLink 地址的浏览器链接
+func (network Network) AddressLink(address string) string {
+	return network.explorerLink("address", address)
+}
+
+func (network Network) explorerLink(kind, id string) string {
+	explorer := strings.TrimSuffix(network.Explorer, "/")
+	if network.Defaults.ExplorerUrl == "" {
+		return fmt.Sprintf("%v/%v/%v", explorer, kind, id)
+	}
+	return strings.NewReplacer(ExplorerPlaceholder, explorer, ExplorerTypePlaceholder, kind, ExplorerIdPlaceholder, id).Replace(network.Defaults.ExplorerUrl)
+}
+
+// UpdateNetworkDefaults 替换network的默认值
+func UpdateNetworkDefaults(name string, defaults NetworkDefaults) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	_, err := QueryNetwork(name)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("network: %s not exist", name)
+	} else if err != nil {
+		return err
+	}
+
+	network := Network{Defaults: defaults}
+	return Conn.WithContext(ctx).Model(&Network{}).Where("name = ?", name).Select("defaults").Updates(&network).Error
+}
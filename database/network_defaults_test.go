@@ -0,0 +1,33 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/database -run 'TestNetworkDefaults'
+func TestNetworkDefaults(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	assert.NoError(t, AddNetwork(&Network{Name: "bsc", Rpc: "http://127.0.0.1:8545", Symbol: "BNB", Explorer: "https://bscscan.com/"}))
+
+	net, err := QueryNetwork("bsc")
+	assert.NoError(t, err)
+	assert.True(t, net.Defaults.IsZero())
+	assert.Equal(t, 20*time.Second, net.RequestTimeout())
+	assert.Equal(t, "https://bscscan.com/tx/0x01", net.TxLink("0x01"))
+
+	confirmations := int8(3)
+	defaults := NetworkDefaults{GasMode: "legacy", Confirmations: &confirmations, RequestTimeout: 60, ExplorerUrl: "{explorer}/#/{type}/{id}"}
+	assert.NoError(t, UpdateNetworkDefaults("bsc", defaults))
+	assert.Error(t, UpdateNetworkDefaults("eth", defaults))
+
+	net, err = QueryNetwork("bsc")
+	assert.NoError(t, err)
+	assert.Equal(t, defaults, net.Defaults)
+	assert.Equal(t, time.Minute, net.RequestTimeout())
+	assert.Equal(t, "https://bscscan.com/#/address/0x02", net.AddressLink("0x02"))
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"met/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	NonceTableName           = "nonces"
+	NonceAllocationTableName = "nonce_allocations"
+)
+
+const (
+	// 已分配，还没有广播
+	NonceAllocated = "allocated"
+	// 已广播
+	NonceSent = "sent"
+	// 没有广播，可以重新分配
+	NonceReleased = "released"
+)
+
+// Nonce 每个(network, address)下一个要分配的nonce
+type Nonce struct {
+	Network   string `gorm:"primaryKey"`
+	Address   string `gorm:"primaryKey"`
+	Next      uint64
+	UpdatedAt time.Time
+}
+
+func (Nonce) TableName() string {
+	return NonceTableName
+}
+
+// NonceAllocation 分配出去的nonce，广播后记录tx hash，没有广播时释放，释放的nonce优先重新分配
+type NonceAllocation struct {
+	ID        uint   `gorm:"primaryKey"`
+	Network   string `gorm:"uniqueIndex:idx_nonce_allocation"`
+	Address   string `gorm:"uniqueIndex:idx_nonce_allocation"`
+	Nonce     uint64 `gorm:"uniqueIndex:idx_nonce_allocation"`
+	Status    string
+	TxHash    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (NonceAllocation) TableName() string {
+	return NonceAllocationTableName
+}
+
+// op
+
+// AllocateNonce 分配一个nonce，chainNonce为链上的pending nonce
+// 数据库中的nonce落后于chainNonce时使用chainNonce，不小于chainNonce的已释放nonce优先分配
+func AllocateNonce(network, address string, chainNonce uint64) (uint64, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var nonce uint64
+	err := Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 第一条语句是写操作，事务开始时就获得写锁，多个进程的分配串行执行
+		err := tx.Exec(`INSERT INTO nonces (network, address, next, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (network, address) DO UPDATE SET next = MAX(next, excluded.next), updated_at = excluded.updated_at`,
+			network, address, chainNonce, time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		var released NonceAllocation
+		err = tx.Where("network = ? AND address = ? AND status = ? AND nonce >= ?", network, address, NonceReleased, chainNonce).
+			Order("nonce").Limit(1).Find(&released).Error
+		if err != nil {
+			return err
+		}
+		if released.ID != 0 {
+			nonce = released.Nonce
+			return tx.Model(&released).Updates(map[string]any{"status": NonceAllocated, "tx_hash": ""}).Error
+		}
+
+		var state Nonce
+		err = tx.Where("network = ? AND address = ?", network, address).First(&state).Error
+		if err != nil {
+			return err
+		}
+		nonce = state.Next
+
+		err = tx.Model(&Nonce{}).Where("network = ? AND address = ?", network, address).
+			Updates(map[string]any{"next": nonce + 1, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return upsertNonceAllocation(tx, network, address, nonce, NonceAllocated, "")
+	})
+	return nonce, err
+}
+
+// MarkNonceSent 记录nonce已经广播，手动指定的nonce超过下一个要分配的nonce时同时更新它
+func MarkNonceSent(network, address string, nonce uint64, txHash string) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO nonces (network, address, next, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (network, address) DO UPDATE SET next = MAX(next, excluded.next), updated_at = excluded.updated_at`,
+			network, address, nonce+1, time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return upsertNonceAllocation(tx, network, address, nonce, NonceSent, txHash)
+	})
+}
+
+// ReleaseNonce 交易没有广播时释放分配的nonce，已经广播的nonce不受影响
+func ReleaseNonce(network, address string, nonce uint64) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Model(&NonceAllocation{}).
+		Where("network = ? AND address = ? AND nonce = ? AND status = ?", network, address, nonce, NonceAllocated).
+		Update("status", NonceReleased).Error
+}
+
+// QueryNonce 没有分配过nonce时返回gorm.ErrRecordNotFound
+func QueryNonce(network, address string) (*Nonce, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var state Nonce
+	err := Conn.WithContext(ctx).Where("network = ? AND address = ?", network, address).First(&state).Error
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// QueryNonceAllocations 按nonce排序
+func QueryNonceAllocations(network, address string) ([]NonceAllocation, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var allocations []NonceAllocation
+	err := Conn.WithContext(ctx).Where("network = ? AND address = ?", network, address).Order("nonce").Find(&allocations).Error
+	return allocations, err
+}
+
+// PruneNonceAllocations 删除小于链上已确认nonce的分配记录
+func PruneNonceAllocations(network, address string, below uint64) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Where("network = ? AND address = ? AND nonce < ?", network, address, below).Delete(&NonceAllocation{}).Error
+}
+
+// ResetNonce 把下一个要分配的nonce设置为next，并删除不小于next的分配记录
+func ResetNonce(network, address string, next uint64) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "network"}, {Name: "address"}},
+			DoUpdates: clause.AssignmentColumns([]string{"next", "updated_at"}),
+		}).Create(&Nonce{Network: network, Address: address, Next: next}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("network = ? AND address = ? AND nonce >= ?", network, address, next).Delete(&NonceAllocation{}).Error
+	})
+}
+
+func upsertNonceAllocation(tx *gorm.DB, network, address string, nonce uint64, status, txHash string) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "network"}, {Name: "address"}, {Name: "nonce"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "tx_hash", "updated_at"}),
+	}).Create(&NonceAllocation{Network: network, Address: address, Nonce: nonce, Status: status, TxHash: txHash}).Error
+}
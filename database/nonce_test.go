@@ -0,0 +1,91 @@
+package database
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/database -run 'TestAllocateNonce'
+func TestAllocateNonce(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	const network, address = "eth", "0x41BB7A889F20b71E6AaBa492298041E84691C41B"
+
+	n, err := AllocateNonce(network, address, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), n)
+
+	// rpc落后时继续使用数据库中的nonce
+	n, err = AllocateNonce(network, address, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), n)
+
+	// 释放的nonce优先分配
+	assert.NoError(t, ReleaseNonce(network, address, 5))
+	n, err = AllocateNonce(network, address, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), n)
+
+	// 已经广播的nonce不能释放
+	assert.NoError(t, MarkNonceSent(network, address, 6, "0x01"))
+	assert.NoError(t, ReleaseNonce(network, address, 6))
+	n, err = AllocateNonce(network, address, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), n)
+
+	// 链上nonce领先时使用链上的nonce
+	n, err = AllocateNonce(network, address, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), n)
+
+	// 手动指定的nonce
+	assert.NoError(t, MarkNonceSent(network, address, 30, "0x02"))
+	state, err := QueryNonce(network, address)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(31), state.Next)
+
+	assert.NoError(t, ResetNonce(network, address, 8))
+	state, err = QueryNonce(network, address)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), state.Next)
+	assert.NoError(t, PruneNonceAllocations(network, address, 6))
+	allocations, err := QueryNonceAllocations(network, address)
+	assert.NoError(t, err)
+	assert.Len(t, allocations, 2)
+	assert.Equal(t, NonceSent, allocations[0].Status)
+	assert.Equal(t, "0x01", allocations[0].TxHash)
+}
+
+// go test -count=1 -v met/database -run 'TestAllocateNonceConcurrently'
+func TestAllocateNonceConcurrently(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	const network, address = "eth", "0x41BB7A889F20b71E6AaBa492298041E84691C41B"
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces []int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := AllocateNonce(network, address, 0)
+			assert.NoError(t, err)
+			mu.Lock()
+			nonces = append(nonces, int(n))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(nonces)
+	for i, n := range nonces {
+		assert.Equal(t, i, n)
+	}
+}
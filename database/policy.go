@@ -0,0 +1,127 @@
+package database
+
+import (
+	"fmt"
+	"met/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Policy 账户签名策略，签名前检查，为空的字段表示不限制
+type Policy struct {
+	AccountName string `gorm:"unique;"`
+
+	// 单笔交易最大native value，单位: ETH
+	MaxValue string
+	// 24小时内(每个network)累计的最大native value，单位: ETH
+	DailyCap string
+
+	// 允许的to地址(接收者或者合约)，逗号分隔
+	AllowedTo string
+	// 允许调用的方法selector，逗号分隔，eg: 0xa9059cbb,0x095ea7b3
+	AllowedSelectors string
+
+	// 最大gasPrice(legacy)或gasFeeCap(eip1559)，单位: gwei
+	MaxFeePerGas string
+}
+
+const (
+	PolicyTableName = "policies"
+)
+
+func (Policy) TableName() string {
+	return PolicyTableName
+}
+
+// Spend 已经发送的交易的native value记录，用于计算daily cap
+type Spend struct {
+	AccountName string `gorm:"index"`
+	Network     string `gorm:"index"`
+	// 单位: wei
+	Value  string
+	TxHash string
+
+	CreatedAt time.Time
+}
+
+const (
+	SpendTableName = "spends"
+)
+
+func (Spend) TableName() string {
+	return SpendTableName
+}
+
+// op
+
+func QueryPolicy(accountName string) (policy Policy, err error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	err = Conn.WithContext(ctx).Model(&Policy{}).First(&policy, "account_name = ?", accountName).Error
+	return
+}
+
+func QueryAllPolicies() (policies []Policy, err error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	err = Conn.WithContext(ctx).Model(&Policy{}).Find(&policies).Error
+	return
+}
+
+// SetPolicy 存在时覆盖，不存在时新建
+func SetPolicy(policy *Policy) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	logger := utils.GetLogger("SetPolicy")
+
+	_, err := QueryAccount(policy.AccountName)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("account: %s not exist", policy.AccountName)
+	} else if err != nil {
+		return err
+	}
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&Policy{}, "account_name = ?", policy.AccountName).Error
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Msgf("set policy for account: %v", policy.AccountName)
+		return tx.Create(policy).Error
+	})
+}
+
+func RemovePolicy(accountName string) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	_, err := QueryPolicy(accountName)
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("policy of account: %s not exist", accountName)
+	} else if err != nil {
+		return err
+	}
+
+	return Conn.WithContext(ctx).Delete(&Policy{}, "account_name = ?", accountName).Error
+}
+
+func AddSpend(spend *Spend) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Create(spend).Error
+}
+
+// QuerySpendsSince 查询账户在某个network上since之后的所有花费
+func QuerySpendsSince(accountName string, network string, since time.Time) (spends []Spend, err error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	err = Conn.WithContext(ctx).Model(&Spend{}).Where("account_name = ? AND network = ? AND created_at >= ?", accountName, network, since).Find(&spends).Error
+	return
+}
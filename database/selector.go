@@ -0,0 +1,205 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 选择器中的前缀，eg: tag:bots group:airdrop[0..49]
+	SelectorTagPrefix   = "tag:"
+	SelectorGroupPrefix = "group:"
+	SelectorAll         = "all"
+
+	// MaxSelectorIndexes 一个index范围最多包含的index数量
+	MaxSelectorIndexes = 10000
+)
+
+// AccountSelector 账户选择器解析后的结果
+type AccountSelector struct {
+	// name, tag, group, all
+	Kind  string
+	Value string
+	// 为空时表示使用账户的current index
+	Indexes []uint
+}
+
+// ParseAccountSelectors 解析逗号分隔的选择器，eg: tag:bots,group:airdrop[0..49],deployer[2]
+func ParseAccountSelectors(selectors string) ([]AccountSelector, error) {
+	var (
+		result []AccountSelector
+		depth  int
+		start  int
+	)
+
+	parts := []string{}
+	for i, c := range selectors {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selectors[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, selectors[start:])
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		selector, err := parseAccountSelector(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, selector)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty account selector")
+	}
+
+	return result, nil
+}
+
+func parseAccountSelector(s string) (AccountSelector, error) {
+	var selector AccountSelector
+
+	body := s
+	if i := strings.Index(s, "["); i >= 0 {
+		if !strings.HasSuffix(s, "]") {
+			return selector, fmt.Errorf("invalid selector: %v, missing ]", s)
+		}
+		indexes, err := parseIndexRange(s[i+1 : len(s)-1])
+		if err != nil {
+			return selector, fmt.Errorf("invalid selector: %v, %w", s, err)
+		}
+		selector.Indexes = indexes
+		body = s[:i]
+	}
+
+	switch {
+	case strings.HasPrefix(body, SelectorTagPrefix):
+		selector.Kind = "tag"
+		selector.Value = strings.TrimPrefix(body, SelectorTagPrefix)
+	case strings.HasPrefix(body, SelectorGroupPrefix):
+		selector.Kind = "group"
+		selector.Value = strings.TrimPrefix(body, SelectorGroupPrefix)
+	case body == SelectorAll:
+		selector.Kind = SelectorAll
+	default:
+		selector.Kind = "name"
+		selector.Value = body
+	}
+
+	if selector.Kind != SelectorAll && selector.Value == "" {
+		return selector, fmt.Errorf("invalid selector: %v", s)
+	}
+
+	return selector, nil
+}
+
+// parseIndexRange 解析 0..49 或 3
+func parseIndexRange(s string) ([]uint, error) {
+	from, to, isRange := strings.Cut(s, "..")
+	start, err := strconv.ParseUint(strings.TrimSpace(from), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index: %v", from)
+	}
+	end := start
+	if isRange {
+		end, err = strconv.ParseUint(strings.TrimSpace(to), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index: %v", to)
+		}
+	}
+	if end < start {
+		return nil, fmt.Errorf("invalid index range: %v", s)
+	}
+	if end-start+1 > MaxSelectorIndexes {
+		return nil, fmt.Errorf("index range: %v exceeds max size: %v", s, MaxSelectorIndexes)
+	}
+
+	indexes := make([]uint, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		indexes = append(indexes, uint(i))
+	}
+	return indexes, nil
+}
+
+// SelectAccounts 根据选择器返回账户列表，每个HD index对应一个账户(CurrentIndex为该index)
+// private key类型的账户忽略index
+func SelectAccounts(selectors string) ([]Account, error) {
+	parsed, err := ParseAccountSelectors(selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := QueryAllAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result []Account
+		seen   = make(map[string]bool)
+	)
+	for _, selector := range parsed {
+		matched := 0
+		for _, acc := range all {
+			if !selector.match(acc) {
+				continue
+			}
+			matched++
+
+			indexes := selector.Indexes
+			// "mnemonic" 即 types.MnemonicType
+			if len(indexes) == 0 || acc.Type != "mnemonic" {
+				indexes = []uint{acc.CurrentIndex}
+			}
+			for _, index := range indexes {
+				key := fmt.Sprintf("%s/%d", acc.Name, index)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				result = append(result, acc.SwitchTo(index))
+			}
+		}
+		if matched == 0 {
+			return nil, fmt.Errorf("no account matches selector: %v:%v", selector.Kind, selector.Value)
+		}
+	}
+
+	return result, nil
+}
+
+func (s AccountSelector) match(acc Account) bool {
+	switch s.Kind {
+	case SelectorAll:
+		return true
+	case "name":
+		return acc.Name == s.Value
+	case "tag":
+		return containsItem(acc.Tags, s.Value)
+	case "group":
+		return containsItem(acc.Groups, s.Value)
+	}
+	return false
+}
+
+// containsItem list是逗号分隔的字符串
+func containsItem(list string, item string) bool {
+	for _, i := range strings.Split(list, ",") {
+		if strings.TrimSpace(i) == item {
+			return true
+		}
+	}
+	return false
+}
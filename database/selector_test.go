@@ -0,0 +1,44 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/database -run 'TestParseAccountSelectors'
+func TestParseAccountSelectors(t *testing.T) {
+	selectors, err := ParseAccountSelectors("tag:bots, group:airdrop[0..2],deployer[5],all")
+	assert.NoError(t, err)
+	assert.Equal(t, []AccountSelector{
+		{Kind: "tag", Value: "bots"},
+		{Kind: "group", Value: "airdrop", Indexes: []uint{0, 1, 2}},
+		{Kind: "name", Value: "deployer", Indexes: []uint{5}},
+		{Kind: SelectorAll},
+	}, selectors)
+
+	for _, s := range []string{"", "tag:", "a[3..1]", "a[x]", "a[1", "a[0..4294967295]", "a[0..10000]"} {
+		_, err := ParseAccountSelectors(s)
+		assert.Error(t, err, s)
+	}
+
+	selectors, err = ParseAccountSelectors("a[0..9999]")
+	assert.NoError(t, err)
+	assert.Len(t, selectors[0].Indexes, MaxSelectorIndexes)
+}
+
+// go test -count=1 -v met/database -run 'TestSelectAccounts'
+func TestSelectAccounts(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	assert.NoError(t, AddAccount(&Account{Name: "pk", Type: "private key", Value: "0x01", Tags: "bots"}))
+	assert.NoError(t, AddAccount(&Account{Name: "hd", Type: "mnemonic", Value: "x", Tags: "bots", Groups: "airdrop"}))
+
+	accounts, err := SelectAccounts("tag:bots,group:airdrop[0..2]")
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 4)
+
+	_, err = SelectAccounts("tag:none")
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"met/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SignKindTransaction = "transaction"
+	SignKindMessage     = "message"
+	SignKindTypedData   = "typed data"
+	// 账户重命名，AccountName为新的账户名，Summary为renamedFrom加旧的账户名
+	SignKindRename = "rename"
+
+	renamedFrom = "renamed from: "
+)
+
+// SigningLog 签名审计日志，每条记录包含上一条记录的hash，形成hash链，用于检测篡改
+type SigningLog struct {
+	ID uint `gorm:"primaryKey"`
+	// unix nano，参与hash计算
+	Timestamp int64
+
+	// transaction, message, typed data, rename
+	Kind         string
+	AccountName  string `gorm:"index"`
+	AccountIndex uint
+	Network      string `gorm:"index"`
+	ChainId      string
+	// 解析后的调用描述
+	Summary string
+	TxHash  string
+	// sent, cancelled, failed: <reason>, rejected-type: <reason>
+	Outcome string
+
+	PrevHash string
+	Hash     string
+}
+
+const (
+	SigningLogTableName = "signing_log"
+)
+
+func (SigningLog) TableName() string {
+	return SigningLogTableName
+}
+
+func (l SigningLog) Time() time.Time {
+	return time.Unix(0, l.Timestamp)
+}
+
+// ComputeHash 计算记录的hash，包含PrevHash，不包含Hash本身
+func (l SigningLog) ComputeHash() string {
+	fields := []string{
+		fmt.Sprintf("%d", l.ID),
+		fmt.Sprintf("%d", l.Timestamp),
+		l.Kind,
+		l.AccountName,
+		fmt.Sprintf("%d", l.AccountIndex),
+		l.Network,
+		l.ChainId,
+		l.Summary,
+		l.TxHash,
+		l.Outcome,
+		l.PrevHash,
+	}
+	h := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(h[:])
+}
+
+// op
+
+// AddSigningLog 追加一条记录，并把它链接到上一条记录
+func AddSigningLog(entry *SigningLog) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return appendSigningLog(tx, entry)
+	})
+}
+
+func appendSigningLog(tx *gorm.DB, entry *SigningLog) error {
+	var last SigningLog
+	err := tx.Model(&SigningLog{}).Order("id desc").Limit(1).Find(&last).Error
+	if err != nil {
+		return err
+	}
+
+	entry.ID = last.ID + 1
+	entry.PrevHash = last.Hash
+	if entry.Timestamp == 0 {
+		entry.Timestamp = time.Now().UnixNano()
+	}
+	entry.Hash = entry.ComputeHash()
+
+	return tx.Create(entry).Error
+}
+
+// addRenameLog 账户名参与hash计算，重命名时不修改已有的记录，追加一条rename记录
+func addRenameLog(tx *gorm.DB, oldName, newName string) error {
+	return appendSigningLog(tx, &SigningLog{Kind: SignKindRename, AccountName: newName, Summary: renamedFrom + oldName})
+}
+
+// signingLogRange 账户名在(After, Before)之间的记录属于同一个账户，Before为0表示不限制
+type signingLogRange struct {
+	Name   string
+	After  uint
+	Before uint
+}
+
+// signingLogRanges 按rename记录找出账户之前使用的账户名
+// 旧的账户名之后可能被新的账户使用，每个账户名只包含对应的id范围
+func signingLogRanges(tx *gorm.DB, accountName string) ([]signingLogRange, error) {
+	var ranges []signingLogRange
+	queue := []signingLogRange{{Name: accountName}}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+
+		// 范围内最后一次从这个账户名重命名之前的记录属于其他账户
+		var renamedAway SigningLog
+		query := tx.Model(&SigningLog{}).Where("kind = ? AND summary = ?", SignKindRename, renamedFrom+r.Name)
+		if r.Before > 0 {
+			query = query.Where("id < ?", r.Before)
+		}
+		err := query.Order("id desc").Limit(1).Find(&renamedAway).Error
+		if err != nil {
+			return nil, err
+		}
+		r.After = renamedAway.ID
+		ranges = append(ranges, r)
+
+		var renames []SigningLog
+		query = tx.Model(&SigningLog{}).Where("kind = ? AND account_name = ? AND id > ?", SignKindRename, r.Name, r.After)
+		if r.Before > 0 {
+			query = query.Where("id < ?", r.Before)
+		}
+		err = query.Find(&renames).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, rename := range renames {
+			if oldName, ok := strings.CutPrefix(rename.Summary, renamedFrom); ok {
+				queue = append(queue, signingLogRange{Name: oldName, Before: rename.ID})
+			}
+		}
+	}
+	return ranges, nil
+}
+
+// QuerySigningLogs 按条件查询，为空的条件表示不限制，limit为0表示不限制
+// 按账户名查询时包含账户重命名之前的记录
+func QuerySigningLogs(accountName string, network string, since time.Time, limit int) (logs []SigningLog, err error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	query := Conn.WithContext(ctx).Model(&SigningLog{})
+	if accountName != "" {
+		ranges, err := signingLogRanges(Conn.WithContext(ctx), accountName)
+		if err != nil {
+			return nil, err
+		}
+		cond := Conn.WithContext(ctx)
+		for _, r := range ranges {
+			if r.Before == 0 {
+				cond = cond.Or("account_name = ? AND id > ?", r.Name, r.After)
+			} else {
+				cond = cond.Or("account_name = ? AND id > ? AND id < ?", r.Name, r.After, r.Before)
+			}
+		}
+		query = query.Where(cond)
+	}
+	if network != "" {
+		query = query.Where("network = ?", network)
+	}
+	if !since.IsZero() {
+		query = query.Where("timestamp >= ?", since.UnixNano())
+	}
+	if limit > 0 {
+		// 取最新的limit条，再按id正序返回
+		query = query.Order("id desc").Limit(limit)
+		err = query.Find(&logs).Error
+		for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+			logs[i], logs[j] = logs[j], logs[i]
+		}
+		return
+	}
+
+	err = query.Order("id asc").Find(&logs).Error
+	return
+}
+
+// VerifySigningLogs 检查整条hash链，返回检查的记录数
+func VerifySigningLogs() (int, error) {
+	logs, err := QuerySigningLogs("", "", time.Time{}, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	prevHash := ""
+	var prevID uint
+	for i, l := range logs {
+		if l.ID != prevID+1 {
+			return i, fmt.Errorf("entry %d: expected id %d, entry missing or reordered", l.ID, prevID+1)
+		}
+		if l.PrevHash != prevHash {
+			return i, fmt.Errorf("entry %d: prev hash mismatch, chain broken", l.ID)
+		}
+		if l.ComputeHash() != l.Hash {
+			return i, fmt.Errorf("entry %d: hash mismatch, entry modified", l.ID)
+		}
+		prevHash = l.Hash
+		prevID = l.ID
+	}
+
+	return len(logs), nil
+}
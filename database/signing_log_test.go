@@ -0,0 +1,75 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/database -run 'TestSigningLogChain'
+func TestSigningLogChain(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	for i := 0; i < 3; i++ {
+		err := AddSigningLog(&SigningLog{
+			Kind:        SignKindTransaction,
+			AccountName: "test",
+			Network:     "eth",
+			ChainId:     "1",
+			Summary:     "native transfer",
+			Outcome:     "sent",
+		})
+		assert.NoError(t, err)
+	}
+
+	n, err := VerifySigningLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// tamper
+	err = Conn.Model(&SigningLog{}).Where("id = ?", 2).Update("outcome", "cancelled").Error
+	assert.NoError(t, err)
+
+	_, err = VerifySigningLogs()
+	assert.Error(t, err)
+	t.Logf("verify error: %v", err)
+}
+
+// go test -count=1 -v met/database -run 'TestSigningLogRename'
+func TestSigningLogRename(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	sign := func(name string) {
+		assert.NoError(t, AddSigningLog(&SigningLog{Kind: SignKindTransaction, AccountName: name, Network: "eth", Outcome: "sent"}))
+	}
+
+	assert.NoError(t, AddAccount(&Account{Name: "a", Type: "private key", Value: "0x01"}))
+	sign("a")
+	assert.NoError(t, UpdateAccount("a", "b", nil))
+	sign("b")
+	assert.NoError(t, UpdateAccount("b", "c", nil))
+	sign("c")
+	// 新账户使用旧的账户名
+	assert.NoError(t, AddAccount(&Account{Name: "a", Type: "private key", Value: "0x02"}))
+	sign("a")
+
+	logs, err := QuerySigningLogs("c", "", time.Time{}, 0)
+	assert.NoError(t, err)
+	var names []string
+	for _, l := range logs {
+		names = append(names, l.Kind+":"+l.AccountName)
+	}
+	assert.Equal(t, []string{"transaction:a", "rename:b", "transaction:b", "rename:c", "transaction:c"}, names)
+
+	// 只包含新账户的记录
+	logs, err = QuerySigningLogs("a", "", time.Time{}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, uint(6), logs[0].ID)
+
+	n, err := VerifySigningLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"met/utils"
+	"time"
+)
+
+const (
+	TransactionTableName = "transactions"
+)
+
+const (
+	TxStatusPending = "pending"
+	// 上链并且执行成功
+	TxStatusMined = "mined"
+	// 上链但是执行失败
+	TxStatusFailed = "failed"
+	// 不在交易池中，nonce也没有被使用
+	TxStatusDropped = "dropped"
+	// nonce被其他交易使用
+	TxStatusReplaced = "replaced"
+)
+
+// Transaction 广播过的交易
+type Transaction struct {
+	ID           uint   `gorm:"primaryKey"`
+	Hash         string `gorm:"uniqueIndex"`
+	Network      string `gorm:"index"`
+	ChainId      string
+	AccountName  string `gorm:"index"`
+	AccountIndex uint
+	From         string `gorm:"index"`
+	To           string
+	Nonce        uint64
+	// 单位wei
+	Value string
+	Type  uint8
+	// 解析后的调用描述
+	Summary string
+	// 签名后的交易，0x开头的hex
+	Raw    string
+	Status string `gorm:"index"`
+
+	BlockNumber uint64
+	GasUsed     uint64
+	// 使用相同nonce并且上链的交易，未知时为空
+	ReplacedBy string
+
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+}
+
+func (Transaction) TableName() string {
+	return TransactionTableName
+}
+
+// TransactionFilter 为空的条件表示不限制，Limit为0表示不限制
+type TransactionFilter struct {
+	AccountName string
+	Network     string
+	Status      string
+	Since       time.Time
+	Until       time.Time
+	Limit       int
+}
+
+// op
+
+func AddTransaction(tx *Transaction) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	if tx.Status == "" {
+		tx.Status = TxStatusPending
+	}
+	return Conn.WithContext(ctx).Create(tx).Error
+}
+
+// QueryTransactions 按创建时间正序返回，有Limit时返回最新的Limit条
+func QueryTransactions(filter TransactionFilter) (txs []Transaction, err error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	query := Conn.WithContext(ctx).Model(&Transaction{})
+	if filter.AccountName != "" {
+		query = query.Where("account_name = ?", filter.AccountName)
+	}
+	if filter.Network != "" {
+		query = query.Where("network = ?", filter.Network)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		query = query.Order("id desc").Limit(filter.Limit)
+		err = query.Find(&txs).Error
+		for i, j := 0, len(txs)-1; i < j; i, j = i+1, j-1 {
+			txs[i], txs[j] = txs[j], txs[i]
+		}
+		return
+	}
+
+	err = query.Order("id asc").Find(&txs).Error
+	return
+}
+
+// QueryMinedTransactionByNonce 查询同一地址使用nonce上链的交易，没有时返回nil
+func QueryMinedTransactionByNonce(network, from string, nonce uint64) (*Transaction, error) {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	var txs []Transaction
+	err := Conn.WithContext(ctx).Where("network = ? AND `from` = ? AND nonce = ? AND status IN ?", network, from, nonce, []string{TxStatusMined, TxStatusFailed}).
+		Limit(1).Find(&txs).Error
+	if err != nil || len(txs) == 0 {
+		return nil, err
+	}
+	return &txs[0], nil
+}
+
+// UpdateTransactionStatus 按hash更新状态和上链信息
+func UpdateTransactionStatus(tx *Transaction) error {
+	ctx, cancel := utils.DefaultTimeoutContext()
+	defer cancel()
+
+	return Conn.WithContext(ctx).Model(&Transaction{}).Where("hash = ?", tx.Hash).
+		Select("status", "block_number", "gas_used", "replaced_by", "updated_at").
+		Updates(tx).Error
+}
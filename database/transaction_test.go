@@ -0,0 +1,63 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/database -run 'TestTransactions'
+func TestTransactions(t *testing.T) {
+	InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	const from = "0x41BB7A889F20b71E6AaBa492298041E84691C41B"
+
+	for i, tx := range []Transaction{
+		{Hash: "0x01", Network: "eth", AccountName: "m", From: from, Nonce: 0},
+		{Hash: "0x02", Network: "eth", AccountName: "m", From: from, Nonce: 1},
+		{Hash: "0x03", Network: "eth", AccountName: "m", From: from, Nonce: 1},
+		{Hash: "0x04", Network: "bsc", AccountName: "a", From: from, Nonce: 0},
+	} {
+		assert.NoError(t, AddTransaction(&tx), i)
+		assert.Equal(t, TxStatusPending, tx.Status)
+	}
+
+	txs, err := QueryTransactions(TransactionFilter{Network: "eth"})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 3)
+	assert.Equal(t, "0x01", txs[0].Hash)
+
+	// 有Limit时返回最新的几条，仍然正序
+	txs, err = QueryTransactions(TransactionFilter{AccountName: "m", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x02", "0x03"}, []string{txs[0].Hash, txs[1].Hash})
+
+	txs, err = QueryTransactions(TransactionFilter{Since: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, txs)
+	txs, err = QueryTransactions(TransactionFilter{Until: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 4)
+
+	tx, err := QueryMinedTransactionByNonce("eth", from, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, tx)
+
+	assert.NoError(t, UpdateTransactionStatus(&Transaction{Hash: "0x03", Status: TxStatusMined, BlockNumber: 10, GasUsed: 21000}))
+	assert.NoError(t, UpdateTransactionStatus(&Transaction{Hash: "0x02", Status: TxStatusReplaced, ReplacedBy: "0x03"}))
+
+	tx, err = QueryMinedTransactionByNonce("eth", from, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x03", tx.Hash)
+	assert.Equal(t, uint64(10), tx.BlockNumber)
+
+	txs, err = QueryTransactions(TransactionFilter{Status: TxStatusPending})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x01", "0x04"}, []string{txs[0].Hash, txs[1].Hash})
+	txs, err = QueryTransactions(TransactionFilter{Status: TxStatusReplaced})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x03", txs[0].ReplacedBy)
+	assert.True(t, txs[0].UpdatedAt.After(txs[0].CreatedAt))
+}
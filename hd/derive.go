@@ -3,6 +3,9 @@ package hd
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,6 +17,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/tyler-smith/go-bip32"
@@ -237,3 +241,74 @@ func CheckHdPath(path string) error {
 	_, err := accounts.ParseDerivationPath(path)
 	return err
 }
+
+// DeriveAddresses 只派生地址，用于批量查找
+// x是path最后一段时，父key只派生一次，子key使用go-ethereum的secp256k1计算，比Derive快很多
+func DeriveAddresses(mnemonic string, passphrase string, path string, start, count uint) (addresses []string, paths []string, err error) {
+	parentPath, last, _ := strings.Cut(path, "/x")
+	if !strings.Contains(path, "/x") || (last != "" && last != "'") {
+		out, err := Derive(mnemonic, passphrase, path, start, count)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range out.Keys {
+			addresses = append(addresses, key.EthereumAddress)
+			paths = append(paths, key.Path)
+		}
+		return addresses, paths, nil
+	}
+	hardened := last == "'"
+
+	rootKey, err := bip32.NewMasterKey(bip39.NewSeed(mnemonic, passphrase))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create master key error: %s", err)
+	}
+	parent := rootKey
+	if parentPath != "m" {
+		parent, err = DeriveByPath(rootKey, parentPath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	parentPrivateKey, err := crypto.ToECDSA(parent.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	parentPubkey := crypto.CompressPubkey(&parentPrivateKey.PublicKey)
+	curveN := crypto.S256().Params().N
+
+	for i := start; i < start+count; i++ {
+		// BIP32 CKDpriv
+		data := make([]byte, 0, 37)
+		index := uint32(i)
+		if hardened {
+			index += QUOTE_PREFIX
+			data = append(data, 0x0)
+			data = append(data, parent.Key...)
+		} else {
+			data = append(data, parentPubkey...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, parent.ChainCode)
+		mac.Write(data)
+		il := new(big.Int).SetBytes(mac.Sum(nil)[:32])
+		if il.Cmp(curveN) >= 0 {
+			return nil, nil, fmt.Errorf("invalid child key at index: %d", i)
+		}
+		childKey := il.Add(il, new(big.Int).SetBytes(parent.Key)).Mod(il, curveN)
+		if childKey.Sign() == 0 {
+			return nil, nil, fmt.Errorf("invalid child key at index: %d", i)
+		}
+
+		privateKey, err := crypto.ToECDSA(math.PaddedBigBytes(childKey, 32))
+		if err != nil {
+			return nil, nil, err
+		}
+		addresses = append(addresses, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+		paths = append(paths, fmt.Sprintf("%s/%d%s", parentPath, i, last))
+	}
+
+	return addresses, paths, nil
+}
@@ -16,3 +16,23 @@ func TestDerive(t *testing.T) {
 	t.Logf("derive result: %v", output.String())
 
 }
+
+// go test -count=1 -v met/hd -run 'TestDeriveAddresses'
+func TestDeriveAddresses(t *testing.T) {
+	mnemonic := "length toddler champion supply hockey orange oil satisfy wisdom hedgehog scene nominee radar cactus immune"
+	for _, path := range []string{"m/44'/60'/0'/0/x", "m/44'/60'/x'", "m/44'/60'/x/0"} {
+		output, err := Derive(mnemonic, "12", path, 3, 3)
+		if err != nil {
+			t.Fatalf("derive error: %s", err)
+		}
+		addresses, paths, err := DeriveAddresses(mnemonic, "12", path, 3, 3)
+		if err != nil {
+			t.Fatalf("derive addresses error: %s", err)
+		}
+		for i, key := range output.Keys {
+			if key.EthereumAddress != addresses[i] || key.Path != paths[i] {
+				t.Fatalf("path: %v index: %d want: %v %v got: %v %v", path, i, key.Path, key.EthereumAddress, paths[i], addresses[i])
+			}
+		}
+	}
+}
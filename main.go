@@ -5,15 +5,17 @@ package main
 
 import (
 	cmd "met/cmd"
-	setup "met/setup"
 
 	_ "met/cmd/account"
 	_ "met/cmd/account/add"
 	_ "met/cmd/account/balance"
 	_ "met/cmd/account/current"
+	_ "met/cmd/account/edit"
+	_ "met/cmd/account/find"
 	_ "met/cmd/account/list"
 	_ "met/cmd/account/lock"
 	_ "met/cmd/account/new"
+	_ "met/cmd/account/passwd"
 	_ "met/cmd/account/rm"
 	_ "met/cmd/account/switch"
 
@@ -21,6 +23,10 @@ import (
 	_ "met/cmd/contract/read"
 	_ "met/cmd/contract/write"
 
+	_ "met/cmd/db"
+	_ "met/cmd/db/migrate"
+	_ "met/cmd/db/status"
+
 	_ "met/cmd/erc20"
 	_ "met/cmd/erc20/allowance"
 	_ "met/cmd/erc20/approve"
@@ -32,26 +38,52 @@ import (
 	_ "met/cmd/erc20/transfer"
 	_ "met/cmd/erc20/transferFrom"
 
+	_ "met/cmd/audit"
+	_ "met/cmd/audit/export"
+	_ "met/cmd/audit/list"
+	_ "met/cmd/audit/verify"
+
 	_ "met/cmd/codec"
 	_ "met/cmd/codec/decode"
 	_ "met/cmd/codec/encode"
 
 	_ "met/cmd/network"
 	_ "met/cmd/network/add"
+	_ "met/cmd/network/check"
 	_ "met/cmd/network/current"
+	_ "met/cmd/network/export"
+	_ "met/cmd/network/import"
 	_ "met/cmd/network/list"
+	_ "met/cmd/network/presets"
+	_ "met/cmd/network/presets/list"
 	_ "met/cmd/network/rm"
+	_ "met/cmd/network/setDefaults"
+	_ "met/cmd/network/setRpcs"
+	_ "met/cmd/network/setTransport"
 	_ "met/cmd/network/switch"
 
 	_ "met/cmd/tx"
+	_ "met/cmd/tx/batch"
+	_ "met/cmd/tx/cancel"
+	_ "met/cmd/tx/get"
+	_ "met/cmd/tx/history"
 	_ "met/cmd/tx/offsign"
+	_ "met/cmd/tx/receipt"
 	_ "met/cmd/tx/send"
+	_ "met/cmd/tx/speedup"
+	_ "met/cmd/tx/sync"
 
-	_ "met/cmd/script"
+	_ "met/cmd/nonce"
+	_ "met/cmd/nonce/fill"
+	_ "met/cmd/nonce/reset"
+	_ "met/cmd/nonce/status"
+
+	_ "met/cmd/policy"
+	_ "met/cmd/policy/rm"
+	_ "met/cmd/policy/set"
+	_ "met/cmd/policy/show"
 )
 
 func main() {
-	setup.SetupDb()
-
 	cmd.Execute()
 }
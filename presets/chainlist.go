@@ -0,0 +1,158 @@
+package presets
+
+import (
+	"encoding/json"
+	"fmt"
+	"met/database"
+	"met/utils"
+	"regexp"
+	"strings"
+)
+
+var (
+	// chainlist中的占位符，eg: https://mainnet.infura.io/v3/${INFURA_API_KEY}
+	chainlistPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// met中的占位符，eg: https://mainnet.infura.io/v3/{INFURA_API_KEY}
+	metPlaceholder = regexp.MustCompile(`\{([A-Z][A-Z0-9_]*)\}`)
+)
+
+// ChainlistEntry chainlist/ethereum-lists chains格式
+type ChainlistEntry struct {
+	Name           string              `json:"name"`
+	Chain          string              `json:"chain"`
+	Rpc            []ChainlistRpc      `json:"rpc"`
+	NativeCurrency ChainlistCurrency   `json:"nativeCurrency"`
+	ChainId        uint64              `json:"chainId"`
+	NetworkId      uint64              `json:"networkId,omitempty"`
+	ShortName      string              `json:"shortName"`
+	Explorers      []ChainlistExplorer `json:"explorers,omitempty"`
+}
+
+// ChainlistRpc chainlist.org中rpc是{url, tracking}对象，ethereum-lists中是字符串
+type ChainlistRpc struct {
+	Url string
+}
+
+func (r *ChainlistRpc) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.Url); err == nil {
+		return nil
+	}
+
+	var obj struct {
+		Url string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("invalid rpc: %s", data)
+	}
+	r.Url = obj.Url
+	return nil
+}
+
+func (r ChainlistRpc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Url)
+}
+
+type ChainlistCurrency struct {
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+type ChainlistExplorer struct {
+	Name     string `json:"name"`
+	Url      string `json:"url"`
+	Standard string `json:"standard,omitempty"`
+}
+
+func ParseChainlist(data []byte) ([]ChainlistEntry, error) {
+	var entries []ChainlistEntry
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("parse chainlist json error: %w", err)
+	}
+	return entries, nil
+}
+
+// ToNetwork network名称使用shortName，只保留http(s) rpc，${NAME}占位符转换为{NAME}
+func (e ChainlistEntry) ToNetwork() (database.Network, error) {
+	network := database.Network{
+		Name:    e.ShortName,
+		Symbol:  e.NativeCurrency.Symbol,
+		ChainId: e.ChainId,
+	}
+	if network.Name == "" {
+		network.Name = strings.ReplaceAll(strings.TrimSpace(e.Name), " ", "-")
+	}
+	if network.Name == "" {
+		return network, fmt.Errorf("chain: %v has no name", e.ChainId)
+	}
+
+	for _, rpc := range e.Rpc {
+		if !strings.HasPrefix(rpc.Url, "http://") && !strings.HasPrefix(rpc.Url, "https://") {
+			continue
+		}
+		url := chainlistPlaceholder.ReplaceAllStringFunc(rpc.Url, func(s string) string {
+			return "{" + strings.ToUpper(s[2:len(s)-1]) + "}"
+		})
+		network.Rpcs = append(network.Rpcs, database.NetworkRpc{Url: url, Weight: 1})
+	}
+	if len(network.Rpcs) == 0 {
+		return network, fmt.Errorf("chain: %v has no http rpc", network.Name)
+	}
+	network.Rpc = network.Rpcs[0].Url
+
+	if len(e.Explorers) > 0 {
+		network.Explorer = strings.TrimSuffix(e.Explorers[0].Url, "/")
+	}
+
+	return network, nil
+}
+
+// FromNetwork {NAME}占位符转换为${NAME}
+// 导出的文件用于分享，用户名密码、query或者path中有明文密钥的rpc放在skipped中，引用原样导出
+func FromNetwork(network database.Network) (entry ChainlistEntry, skipped []string) {
+	entry = ChainlistEntry{
+		Name:      network.Name,
+		Chain:     network.Symbol,
+		ChainId:   network.ChainId,
+		NetworkId: network.ChainId,
+		ShortName: network.Name,
+		NativeCurrency: ChainlistCurrency{
+			Name:     network.Symbol,
+			Symbol:   network.Symbol,
+			Decimals: 18,
+		},
+		Rpc: []ChainlistRpc{},
+	}
+
+	for _, endpoint := range network.Endpoints() {
+		if utils.HasLiteralSecret(endpoint.Url) {
+			skipped = append(skipped, utils.RedactUrl(endpoint.Url))
+			continue
+		}
+		url := exportPlaceholders(endpoint.Url)
+		entry.Rpc = append(entry.Rpc, ChainlistRpc{Url: url})
+	}
+
+	if network.Explorer != "" {
+		entry.Explorers = append(entry.Explorers, ChainlistExplorer{Name: "explorer", Url: network.Explorer, Standard: "EIP3091"})
+	}
+
+	return entry, skipped
+}
+
+// exportPlaceholders {NAME}转换为${NAME}，已经是${NAME}的引用不变
+func exportPlaceholders(url string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range metPlaceholder.FindAllStringSubmatchIndex(url, -1) {
+		if m[0] > 0 && url[m[0]-1] == '$' {
+			continue
+		}
+		b.WriteString(url[last:m[0]])
+		b.WriteString("${" + url[m[2]:m[3]] + "}")
+		last = m[1]
+	}
+	b.WriteString(url[last:])
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package presets
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// networks.json 修改网络列表时增加version
+//
+//go:embed networks.json
+var networksJson []byte
+
+type Preset struct {
+	Name     string `json:"name"`
+	ChainId  uint64 `json:"chainId"`
+	Symbol   string `json:"symbol"`
+	Explorer string `json:"explorer"`
+	Eip1559  bool   `json:"eip1559"`
+	Testnet  bool   `json:"testnet"`
+	// 有序的rpc列表，可以包含{INFURA_KEY}这样的占位符，dial时从环境变量替换
+	Rpcs []string `json:"rpcs"`
+}
+
+type Catalog struct {
+	Version  int      `json:"version"`
+	Networks []Preset `json:"networks"`
+}
+
+func Load() (*Catalog, error) {
+	var catalog Catalog
+	err := json.Unmarshal(networksJson, &catalog)
+	if err != nil {
+		return nil, fmt.Errorf("parse network presets error: %w", err)
+	}
+	return &catalog, nil
+}
+
+func Find(name string) (*Preset, error) {
+	catalog, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range catalog.Networks {
+		if catalog.Networks[i].Name == name {
+			return &catalog.Networks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("preset: %v not exist", name)
+}
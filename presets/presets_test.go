@@ -0,0 +1,29 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/presets -run 'TestCatalog'
+func TestCatalog(t *testing.T) {
+	catalog, err := Load()
+	assert.NoError(t, err)
+	assert.NotZero(t, catalog.Version)
+
+	names := make(map[string]bool)
+	for _, preset := range catalog.Networks {
+		assert.False(t, names[preset.Name], "duplicate preset: %v", preset.Name)
+		names[preset.Name] = true
+
+		assert.NotZero(t, preset.ChainId, preset.Name)
+		assert.NotEmpty(t, preset.Symbol, preset.Name)
+		assert.NotEmpty(t, preset.Rpcs, preset.Name)
+	}
+
+	_, err = Find("polygon")
+	assert.NoError(t, err)
+	_, err = Find("goerli")
+	assert.Error(t, err)
+}
@@ -3,8 +3,10 @@ package setup
 import (
 	"fmt"
 	database "met/database"
+	utils "met/utils"
 	"os"
 	"path"
+	"path/filepath"
 )
 
 func defaultDbPath() string {
@@ -18,11 +20,20 @@ func defaultDbPath() string {
 	return path.Join(dir, "met.db")
 }
 
-func SetupDb() {
-
-	dbPath := os.Getenv("met_db")
+// SetupDb dbPath为空时使用~/.met/met.db，migrate为true时执行未执行的migration
+func SetupDb(dbPath string, migrate bool) {
 	if dbPath == "" {
 		dbPath = defaultDbPath()
+	} else {
+		dbPath = utils.ExpandHome(dbPath)
+		err := os.MkdirAll(filepath.Dir(dbPath), 0755)
+		if err != nil {
+			panic(fmt.Sprintf("create db dir: %s error: %v", filepath.Dir(dbPath), err))
+		}
+	}
+	if migrate {
+		database.InitDB("error", dbPath)
+	} else {
+		database.OpenDB("error", dbPath)
 	}
-	database.InitDB("error", dbPath)
 }
@@ -0,0 +1,123 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"met/utils"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// AccessListAuto 使用eth_createAccessList生成access list
+const AccessListAuto = "auto"
+
+// ResolveAccessList accessList为auto时使用eth_createAccessList生成，节省gas时才返回生成的list
+// 否则作为json文件读取，为空时返回nil
+func ResolveAccessList(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg, accessList string) (types.AccessList, error) {
+	switch accessList {
+	case "":
+		return nil, nil
+	case AccessListAuto:
+		return CreateAccessList(ctx, client, msg)
+	default:
+		return LoadAccessList(accessList)
+	}
+}
+
+// CreateAccessList 生成access list并分别估算使用和不使用access list的gas，不节省gas时返回nil
+func CreateAccessList(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg) (types.AccessList, error) {
+	logger := utils.GetLogger("CreateAccessList")
+
+	logger.Debug().Msgf("create access list..")
+	var result struct {
+		AccessList *types.AccessList `json:"accessList"`
+		Error      string            `json:"error,omitempty"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+	}
+	err := client.Client().CallContext(ctx, &result, "eth_createAccessList", accessListCallArg(msg), "pending")
+	if err != nil {
+		return nil, fmt.Errorf("eth_createAccessList error: %w", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("eth_createAccessList execution error: %v", result.Error)
+	}
+	list := result.AccessList
+	if list == nil || len(*list) == 0 {
+		logger.Info().Msgf("Access list: empty, not attached")
+		return nil, nil
+	}
+
+	msg.AccessList = nil
+	gasWithout, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas without access list error: %w", DecodeRevertError(err, builtinAbis()))
+	}
+	msg.AccessList = *list
+	gasWith, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas with access list error: %w", DecodeRevertError(err, builtinAbis()))
+	}
+
+	if gasWith >= gasWithout {
+		logger.Info().Msgf("Access list: not attached, gas with list: %v without: %v", gasWith, gasWithout)
+		return nil, nil
+	}
+	logger.Info().Msgf("Access list: attached, saves %v gas (%v -> %v)", gasWithout-gasWith, gasWithout, gasWith)
+	return *list, nil
+}
+
+// accessListCallArg eth_createAccessList的交易参数，ethclient没有导出toCallArg
+func accessListCallArg(msg ethereum.CallMsg) map[string]any {
+	arg := map[string]any{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	return arg
+}
+
+// LoadAccessList 读取json格式的access list
+// eg: [{"address":"0x...","storageKeys":["0x..."]}]
+func LoadAccessList(path string) (types.AccessList, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read access list file error: %w", err)
+	}
+	return ParseAccessList(content)
+}
+
+func ParseAccessList(content []byte) (types.AccessList, error) {
+	var list types.AccessList
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, fmt.Errorf("parse access list error: %w", err)
+	}
+	return list, nil
+}
+
+// FormatAccessList 每个地址一行，storage key缩进显示在地址下面
+func FormatAccessList(list types.AccessList) string {
+	if len(list) == 0 {
+		return "[]"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v addresses, %v storage keys", len(list), list.StorageKeys())
+	for _, tuple := range list {
+		fmt.Fprintf(&b, "\n  %s", tuple.Address.Hex())
+		for _, key := range tuple.StorageKeys {
+			fmt.Fprintf(&b, "\n    %s", key.Hex())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,24 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestAccessList'
+func TestAccessList(t *testing.T) {
+	list, err := ParseAccessList([]byte(`[{"address":"0x434df76d002f3fc2aefb0713ffbb003494e8581d","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]},{"address":"0x41bb7a889f20b71e6aaba492298041e84691c41b","storageKeys":[]}]`))
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, 1, list.StorageKeys())
+
+	assert.Equal(t, `2 addresses, 1 storage keys
+  0x434DF76D002f3fc2AeFB0713FFBB003494E8581D
+    0x0000000000000000000000000000000000000000000000000000000000000001
+  0x41BB7A889F20b71E6AaBa492298041E84691C41B`, FormatAccessList(list))
+	assert.Equal(t, "[]", FormatAccessList(nil))
+
+	_, err = ParseAccessList([]byte(`[{"address":"0x01","storageKeys":["0x01"]}]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package transaction
+
+import (
+	"fmt"
+	"met/database"
+	utils "met/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	OutcomeSent      = "sent"
+	OutcomeCancelled = "cancelled"
+	OutcomeFailed    = "failed"
+	// 节点不支持交易类型，使用type 0重新签名
+	OutcomeRejectedType = "rejected-type"
+)
+
+// RecordTxSignature 把一次交易签名写入签名审计日志，err不为空时outcome记为failed
+func RecordTxSignature(accountName string, accountIndex uint, networkName string, tx *types.Transaction, outcome string, err error) {
+	logger := utils.GetLogger("RecordTxSignature")
+
+	if err != nil {
+		outcome = fmt.Sprintf("%s: %v", OutcomeFailed, err)
+	}
+
+	entry := database.SigningLog{
+		Kind:         database.SignKindTransaction,
+		AccountName:  accountName,
+		AccountIndex: accountIndex,
+		Network:      networkName,
+		ChainId:      tx.ChainId().String(),
+		Summary:      CallSummary(tx),
+		TxHash:       tx.Hash().Hex(),
+		Outcome:      outcome,
+	}
+	if dbErr := database.AddSigningLog(&entry); dbErr != nil {
+		logger.Error().Err(dbErr).Msgf("record signature of tx: %v", tx.Hash())
+	}
+}
+
+// RecordingSigner 包装bind.SignerFn，把签名后的交易保存到signed中，
+// 用于bind.TransactOpts发送失败时也能记录签名
+func RecordingSigner(signerFn bind.SignerFn, signed **types.Transaction) bind.SignerFn {
+	return func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		signedTx, err := signerFn(address, tx)
+		if err == nil {
+			*signed = signedTx
+		}
+		return signedTx, err
+	}
+}
+
+// CallSummary 返回交易的可读描述，input能用内置abi(erc20 erc721 erc1155)解析时显示方法和参数
+func CallSummary(tx *types.Transaction) string {
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	summary := fmt.Sprintf("to: %s value: %s wei", to, tx.Value().String())
+
+	data := tx.Data()
+	if len(data) == 0 {
+		return summary
+	}
+	if tx.To() == nil {
+		return fmt.Sprintf("%s deploy: %d bytes", summary, len(data))
+	}
+
+	return fmt.Sprintf("%s call: %s", summary, DecodeCall(data))
+}
+
+// DecodeCall 使用内置abi解析input，无法解析时返回selector
+func DecodeCall(data []byte) string {
+	if len(data) < 4 {
+		return fmt.Sprintf("0x%x", data)
+	}
+
+	abis, _ := DecodeAbis("")
+	if decoded, ok := DecodeCallWith(abis, data); ok {
+		return decoded
+	}
+
+	return fmt.Sprintf("0x%x (%d bytes)", data[:4], len(data))
+}
+
+func decodeCallWithAbi(abiObj *abi.ABI, data []byte) (string, bool) {
+	method, err := abiObj.MethodById(data[:4])
+	if err != nil {
+		return "", false
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", false
+	}
+
+	var argStrs []string
+	for _, arg := range args {
+		argStrs = append(argStrs, formatArg(arg))
+	}
+	return fmt.Sprintf("%s(%s)", method.Sig, strings.Join(argStrs, ", ")), true
+}
@@ -0,0 +1,604 @@
+package transaction
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"met/consts"
+	"met/database"
+	mTypes "met/types"
+	"met/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// 等待交易上链时查询回执的间隔
+	batchPollInterval = 2 * time.Second
+)
+
+var (
+	batchColumns       = []string{"to", "value", "data", "token", "amount"}
+	batchResultColumns = []string{"row", "to", "value", "data", "token", "amount", "nonce", "hash", "status", "error"}
+)
+
+// BatchOptions Window为同时在交易池中等待上链的最大交易数，gas参数与tx send相同
+// Timeout为整个batch等待交易上链的最长时间，0表示交易还在交易池中时一直等待
+type BatchOptions struct {
+	Window   int
+	Timeout  time.Duration
+	GasMode  mTypes.GasMode
+	GasRatio string
+	GasPrice string
+	TipCap   string
+	FeeCap   string
+}
+
+// BatchCall 解析后的一行，Data为erc20 transfer或者文件中的data
+type BatchCall struct {
+	Item  *database.BatchItem
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+	Gas   uint64
+}
+
+// BatchTokenTotal 一个代币需要转出的总数和余额，单位为代币的最小单位
+type BatchTokenTotal struct {
+	Token    common.Address
+	Decimals uint8
+	Amount   *big.Int
+	Balance  *big.Int
+}
+
+// BatchEstimate 待发送行的总花费，Fee按gas limit和最高gas价格计算
+type BatchEstimate struct {
+	Calls     []BatchCall
+	Value     *big.Int
+	Gas       uint64
+	FeePerGas *big.Int
+	Fee       *big.Int
+	Balance   *big.Int
+	Tokens    []*BatchTokenTotal
+}
+
+// ReadBatchFile 读取csv，第一行为表头，列为to,value,data或者to,token,amount
+// value和amount为人类可读的数量，以#开头的行为注释
+func ReadBatchFile(r io.Reader) ([]database.BatchItem, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty batch file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header error: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(batchColumns, name) {
+			return nil, fmt.Errorf("unknown column: %v, available columns: %v", name, strings.Join(batchColumns, ","))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate column: %v", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["to"]; !ok {
+		return nil, errors.New("missing column: to")
+	}
+
+	var items []database.BatchItem
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		items = append(items, database.BatchItem{
+			Row:    line,
+			To:     field("to"),
+			Value:  field("value"),
+			Data:   field("data"),
+			Token:  field("token"),
+			Amount: field("amount"),
+			Status: database.BatchItemPending,
+		})
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no rows in batch file")
+	}
+	return items, nil
+}
+
+// WriteBatchResult 写入每一行的nonce、tx hash和状态
+func WriteBatchResult(w io.Writer, items []database.BatchItem) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(batchResultColumns)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var nonce string
+		if item.Status != database.BatchItemPending {
+			nonce = strconv.FormatUint(item.Nonce, 10)
+		}
+		err = writer.Write([]string{
+			strconv.Itoa(item.Row), item.To, item.Value, item.Data, item.Token, item.Amount,
+			nonce, item.Hash, item.Status, item.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// CheckAddress 地址包含大小写时检查EIP-55 checksum
+func CheckAddress(address string) (common.Address, error) {
+	if !utils.IsValidAddress(address) {
+		return common.Address{}, fmt.Errorf("invalid address: %v", address)
+	}
+	addr := common.HexToAddress(address)
+	digits := address[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && address != addr.Hex() {
+		return common.Address{}, fmt.Errorf("invalid address checksum: %v (expected: %v)", address, addr.Hex())
+	}
+	return addr, nil
+}
+
+// ValidateBatch 解析所有行，在pending区块上模拟并估算gas，再检查native和代币余额是否足够支付所有交易和手续费
+// 返回的错误包含所有无效的行
+func ValidateBatch(ctx context.Context, client *ethclient.Client, net *database.Network, from common.Address, items []*database.BatchItem, opts *BatchOptions) (*BatchEstimate, error) {
+	logger := utils.GetLogger("ValidateBatch")
+
+	estimate := &BatchEstimate{Value: big.NewInt(0), Fee: big.NewInt(0)}
+	tokens := make(map[common.Address]*BatchTokenTotal)
+	abis := builtinAbis()
+
+	var errs []error
+	for _, item := range items {
+		call, err := parseBatchItem(ctx, client, item, tokens, &estimate.Tokens)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("row %d: %w", item.Row, err))
+			continue
+		}
+
+		msg := ethereum.CallMsg{
+			From:  from,
+			To:    &call.To,
+			Value: call.Value,
+			Data:  call.Data,
+		}
+		sim, err := Simulate(ctx, client, msg, abis)
+		if err != nil {
+			return nil, err
+		}
+		if sim.Reverted {
+			errs = append(errs, fmt.Errorf("row %d: %w: %v", item.Row, ErrSimulationReverted, sim.Result))
+			continue
+		}
+
+		call.Gas, err = client.EstimateGas(ctx, msg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("row %d: estimate gas error: %w", item.Row, DecodeRevertError(err, abis)))
+			continue
+		}
+
+		estimate.Calls = append(estimate.Calls, *call)
+		estimate.Value.Add(estimate.Value, call.Value)
+		estimate.Gas += call.Gas
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(estimate.Calls) == 0 {
+		return estimate, nil
+	}
+
+	// 使用与发送时相同的参数构造一个交易，得到最高gas价格
+	zero := "0"
+	sample, err := BuildTx(client, net, from.Hex(), from.Hex(), &zero, nil, false, opts.GasMode, mTypes.TxTypeUnspecified, "0", "", strconv.FormatUint(OnlyTransferGas, 10), "", opts.GasRatio, opts.GasPrice, opts.TipCap, opts.FeeCap, false, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("query gas price error: %w", err)
+	}
+	estimate.FeePerGas = sample.GasFeeCap()
+	estimate.Fee = new(big.Int).Mul(estimate.FeePerGas, new(big.Int).SetUint64(estimate.Gas))
+
+	estimate.Balance, err = client.PendingBalanceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("query balance error: %w", err)
+	}
+	logger.Debug().Msgf("value: %v fee: %v balance: %v", estimate.Value, estimate.Fee, estimate.Balance)
+
+	total := new(big.Int).Add(estimate.Value, estimate.Fee)
+	if total.Cmp(estimate.Balance) > 0 {
+		errs = append(errs, fmt.Errorf("insufficient balance: %v wei, need %v wei (value: %v fee: %v)", estimate.Balance, total, estimate.Value, estimate.Fee))
+	}
+	for _, token := range estimate.Tokens {
+		erc20, err := utils.NewErc20Caller(token.Token, client)
+		if err != nil {
+			return nil, err
+		}
+		token.Balance, err = erc20.BalanceOf(&bind.CallOpts{Context: ctx, Pending: true}, from)
+		if err != nil {
+			return nil, fmt.Errorf("query balance of token: %v error: %w", token.Token, err)
+		}
+		if token.Amount.Cmp(token.Balance) > 0 {
+			errs = append(errs, fmt.Errorf("insufficient balance of token: %v, balance: %v need: %v", token.Token, token.Balance, token.Amount))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return estimate, nil
+}
+
+func parseBatchItem(ctx context.Context, client *ethclient.Client, item *database.BatchItem, tokens map[common.Address]*BatchTokenTotal, tokenList *[]*BatchTokenTotal) (*BatchCall, error) {
+	to, err := CheckAddress(item.To)
+	if err != nil {
+		return nil, err
+	}
+	call := &BatchCall{Item: item, To: to, Value: big.NewInt(0)}
+
+	if item.Token != "" || item.Amount != "" {
+		if item.Token == "" || item.Amount == "" {
+			return nil, errors.New("token and amount must be set together")
+		}
+		if item.Value != "" || item.Data != "" {
+			return nil, errors.New("token transfer conflicts with value and data")
+		}
+		token, err := CheckAddress(item.Token)
+		if err != nil {
+			return nil, fmt.Errorf("token: %w", err)
+		}
+
+		total, ok := tokens[token]
+		if !ok {
+			erc20, err := utils.NewErc20Caller(token, client)
+			if err != nil {
+				return nil, err
+			}
+			decimals, err := erc20.Decimals(&bind.CallOpts{Context: ctx})
+			if err != nil {
+				return nil, fmt.Errorf("query decimals of token: %v error: %w", token, err)
+			}
+			total = &BatchTokenTotal{Token: token, Decimals: decimals, Amount: big.NewInt(0)}
+			tokens[token] = total
+			*tokenList = append(*tokenList, total)
+		}
+
+		amountStr, err := utils.Erc20AmountFromHuman(item.Amount, strconv.Itoa(int(total.Decimals)))
+		if err != nil {
+			return nil, err
+		}
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok || amount.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid amount: %v (decimals: %v)", item.Amount, total.Decimals)
+		}
+		call.Data, err = ParseInput("", consts.Erc20, consts.Erc20Transfer, to.Hex(), amount.String())
+		if err != nil {
+			return nil, err
+		}
+		call.To = token
+		total.Amount.Add(total.Amount, amount)
+		return call, nil
+	}
+
+	if item.Value == "" && item.Data == "" {
+		return nil, errors.New("missing value, data or token")
+	}
+	if item.Value != "" {
+		call.Value, err = utils.ParseUnits(item.Value, utils.UnitEth)
+		if err != nil {
+			return nil, fmt.Errorf("parse value: %v error: %w", item.Value, err)
+		}
+		if call.Value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid value: %v", item.Value)
+		}
+	}
+	if item.Data != "" {
+		call.Data, err = hex.DecodeString(strings.TrimPrefix(item.Data, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("parse data error: %w", err)
+		}
+	}
+	return call, nil
+}
+
+// BatchPolicyTxs 用于在发送之前按账户策略检查所有交易
+func BatchPolicyTxs(estimate *BatchEstimate) []*types.Transaction {
+	txs := make([]*types.Transaction, 0, len(estimate.Calls))
+	for _, call := range estimate.Calls {
+		txs = append(txs, types.NewTx(&types.DynamicFeeTx{
+			To:        &call.To,
+			Value:     call.Value,
+			Data:      call.Data,
+			Gas:       call.Gas,
+			GasFeeCap: estimate.FeePerGas,
+		}))
+	}
+	return txs
+}
+
+// RunBatch 先处理上次中断时已签名或已广播的行，再按顺序签名并广播calls，最多Window个交易同时等待上链
+// 每个交易在广播之前保存到数据库，中断后重新执行时只会重新广播相同的交易，不会重复支付
+// 账户策略需要在调用之前用CheckBatchPolicy检查
+func RunBatch(ctx context.Context, client *ethclient.Client, net *database.Network, batch *database.Batch, items []database.BatchItem, calls []BatchCall, privateKey *ecdsa.PrivateKey, opts *BatchOptions) error {
+	logger := utils.GetLogger("RunBatch")
+
+	chainID, err := VerifyChainId(ctx, client, net)
+	if err != nil {
+		return err
+	}
+
+	r := &batchRunner{
+		client:     client,
+		net:        net,
+		batch:      batch,
+		from:       common.HexToAddress(batch.From),
+		signer:     types.LatestSignerForChainID(chainID),
+		privateKey: privateKey,
+		opts:       opts,
+		started:    time.Now(),
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.Status == database.BatchItemSigned || item.Status == database.BatchItemSent {
+			logger.Info().Msgf("row %d: resume tx: %v nonce: %v", item.Row, item.Hash, item.Nonce)
+			r.inflight = append(r.inflight, item)
+		}
+	}
+
+	for i := range calls {
+		err = r.wait(ctx, max(opts.Window, 1))
+		if err != nil {
+			return err
+		}
+		err = r.send(ctx, &calls[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	// 等待所有交易上链
+	return r.wait(ctx, 1)
+}
+
+type batchRunner struct {
+	client     *ethclient.Client
+	net        *database.Network
+	batch      *database.Batch
+	from       common.Address
+	signer     types.Signer
+	privateKey *ecdsa.PrivateKey
+	opts       *BatchOptions
+	started    time.Time
+
+	// 已签名或已广播，还没有上链的行
+	inflight []*database.BatchItem
+}
+
+// wait 等待直到在交易池中的交易少于limit，check会重新广播不在交易池中的交易
+// 超过opts.Timeout时返回错误，下次运行时继续
+func (r *batchRunner) wait(ctx context.Context, limit int) error {
+	for {
+		err := r.poll(ctx)
+		if err != nil {
+			return err
+		}
+		if len(r.inflight) < limit {
+			return nil
+		}
+		if r.opts.Timeout > 0 && time.Since(r.started) > r.opts.Timeout {
+			return fmt.Errorf("%v txs are still pending after %v, run again later to resume", len(r.inflight), r.opts.Timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(batchPollInterval):
+		}
+	}
+}
+
+// poll 检查所有等待中的交易，移除已经上链或者被替换的
+func (r *batchRunner) poll(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.net.RequestTimeout())
+	defer cancel()
+
+	var pending []*database.BatchItem
+	for i, item := range r.inflight {
+		done, err := r.check(ctx, item)
+		if err != nil {
+			r.inflight = append(pending, r.inflight[i:]...)
+			return err
+		}
+		if !done {
+			pending = append(pending, item)
+		}
+	}
+	r.inflight = pending
+	return nil
+}
+
+// check 返回交易是否已经结束，不在交易池中并且nonce没有被使用时重新广播
+func (r *batchRunner) check(ctx context.Context, item *database.BatchItem) (bool, error) {
+	logger := utils.GetLogger("RunBatch")
+
+	hash := common.HexToHash(item.Hash)
+	receipt, err := r.client.TransactionReceipt(ctx, hash)
+	if err == nil {
+		return true, r.mined(item, receipt)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return false, fmt.Errorf("row %d: query receipt: %v error: %w", item.Row, item.Hash, err)
+	}
+
+	tx := new(types.Transaction)
+	err = tx.UnmarshalBinary(common.FromHex(item.Raw))
+	if err != nil {
+		return false, fmt.Errorf("row %d: decode raw tx error: %w", item.Row, err)
+	}
+
+	_, _, err = r.client.TransactionByHash(ctx, hash)
+	if err == nil {
+		if item.Status == database.BatchItemSigned {
+			// 中断之前已经广播
+			return false, r.sent(item, tx)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return false, fmt.Errorf("row %d: query tx: %v error: %w", item.Row, item.Hash, err)
+	}
+
+	latest, err := r.client.NonceAt(ctx, r.from, nil)
+	if err != nil {
+		return false, fmt.Errorf("query nonce error: %w", err)
+	}
+	if latest > item.Nonce {
+		// 查询nonce之前可能刚好上链
+		receipt, err = r.client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return true, r.mined(item, receipt)
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return false, fmt.Errorf("row %d: query receipt: %v error: %w", item.Row, item.Hash, err)
+		}
+
+		logger.Warn().Msgf("row %d: nonce: %v is used by another tx, tx: %v will not be mined", item.Row, item.Nonce, item.Hash)
+		item.Status = database.BatchItemReplaced
+		item.Error = "nonce used by another tx"
+		return true, database.UpdateBatchItem(item)
+	}
+
+	logger.Info().Msgf("row %d: tx: %v is not in pool, broadcast again", item.Row, item.Hash)
+	return false, r.broadcast(ctx, item, tx)
+}
+
+// send 构造并签名交易，保存到数据库之后再广播
+func (r *batchRunner) send(ctx context.Context, call *BatchCall) error {
+	logger := utils.GetLogger("RunBatch")
+	item := call.Item
+
+	value, err := utils.FormatUnits(call.Value.String(), utils.UnitEth)
+	if err != nil {
+		return fmt.Errorf("row %d: %w", item.Row, err)
+	}
+	tx, err := BuildTx(r.client, r.net, r.from.Hex(), call.To.Hex(), &value, call.Data, false, r.opts.GasMode, mTypes.TxTypeUnspecified, "", "", strconv.FormatUint(call.Gas, 10), "", r.opts.GasRatio, r.opts.GasPrice, r.opts.TipCap, r.opts.FeeCap, false, "", nil)
+	if err != nil {
+		return fmt.Errorf("row %d: build tx error: %w", item.Row, err)
+	}
+
+	nonce := tx.Nonce()
+	tx, err = types.SignTx(tx, r.signer, r.privateKey)
+	var raw []byte
+	if err == nil {
+		raw, err = tx.MarshalBinary()
+	}
+	if err == nil {
+		item.Nonce = nonce
+		item.Hash = tx.Hash().Hex()
+		item.Raw = "0x" + hex.EncodeToString(raw)
+		item.Status = database.BatchItemSigned
+		item.Error = ""
+		err = database.UpdateBatchItem(item)
+	}
+	if err != nil {
+		recordNonce(r.net.Name, r.batch.From, nonce, "", false, true)
+		return fmt.Errorf("row %d: %w", item.Row, err)
+	}
+
+	logger.Info().Msgf("row %d: send tx: %v nonce: %v", item.Row, item.Hash, nonce)
+	err = r.broadcast(ctx, item, tx)
+	if err != nil {
+		return err
+	}
+	r.inflight = append(r.inflight, item)
+	return nil
+}
+
+// broadcast 广播失败时保留签名的交易，下次执行时重新广播
+func (r *batchRunner) broadcast(ctx context.Context, item *database.BatchItem, tx *types.Transaction) error {
+	logger := utils.GetLogger("RunBatch")
+
+	ctx, cancel := context.WithTimeout(ctx, r.net.RequestTimeout())
+	defer cancel()
+
+	err := r.client.SendTransaction(ctx, tx)
+	if err != nil && strings.Contains(err.Error(), "already known") {
+		err = nil
+	}
+	if item.Status == database.BatchItemSigned {
+		RecordTxSignature(r.batch.AccountName, r.batch.AccountIndex, r.net.Name, tx, OutcomeSent, err)
+	}
+	if err != nil {
+		item.Error = err.Error()
+		if err := database.UpdateBatchItem(item); err != nil {
+			logger.Error().Err(err).Msgf("save row: %v", item.Row)
+		}
+		return fmt.Errorf("row %d: send tx: %v error: %w", item.Row, item.Hash, err)
+	}
+
+	if item.Status == database.BatchItemSigned {
+		return r.sent(item, tx)
+	}
+	return nil
+}
+
+func (r *batchRunner) sent(item *database.BatchItem, tx *types.Transaction) error {
+	item.Status = database.BatchItemSent
+	item.Error = ""
+	err := database.UpdateBatchItem(item)
+	if err != nil {
+		return fmt.Errorf("row %d: %w", item.Row, err)
+	}
+	RecordSpend(r.batch.AccountName, r.net.Name, tx)
+	RecordTransaction(r.batch.AccountName, r.batch.AccountIndex, r.net.Name, tx)
+	recordNonce(r.net.Name, r.batch.From, item.Nonce, item.Hash, true, true)
+	return nil
+}
+
+func (r *batchRunner) mined(item *database.BatchItem, receipt *types.Receipt) error {
+	logger := utils.GetLogger("RunBatch")
+
+	item.Status = database.BatchItemMined
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		item.Status = database.BatchItemFailed
+	}
+	item.Error = ""
+	logger.Info().Msgf("row %d: tx: %v %v in block: %v", item.Row, item.Hash, item.Status, receipt.BlockNumber)
+	RecordReceipt(receipt)
+	return database.UpdateBatchItem(item)
+}
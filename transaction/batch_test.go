@@ -0,0 +1,59 @@
+package transaction
+
+import (
+	"bytes"
+	"met/database"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestBatchFile'
+func TestBatchFile(t *testing.T) {
+	items, err := ReadBatchFile(strings.NewReader(`To, Value, Token, Amount
+# comment
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23, 0.1,,
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23,,0x0000000000000000000000000000000000000001,5
+`))
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, 3, items[0].Row)
+	assert.Equal(t, "0.1", items[0].Value)
+	assert.Equal(t, 4, items[1].Row)
+	assert.Equal(t, "5", items[1].Amount)
+	assert.Equal(t, database.BatchItemPending, items[1].Status)
+
+	_, err = ReadBatchFile(strings.NewReader("to,foo\n0x01,1\n"))
+	assert.ErrorContains(t, err, "unknown column")
+	_, err = ReadBatchFile(strings.NewReader("value\n1\n"))
+	assert.ErrorContains(t, err, "missing column: to")
+	_, err = ReadBatchFile(strings.NewReader("to,value\n"))
+	assert.Error(t, err)
+
+	items[1].Nonce = 7
+	items[1].Hash = "0x01"
+	items[1].Status = database.BatchItemMined
+	var buf bytes.Buffer
+	assert.NoError(t, WriteBatchResult(&buf, items))
+	assert.Equal(t, `row,to,value,data,token,amount,nonce,hash,status,error
+3,0x2c7536E3605D9C16a7a3D7b1898e529396a65c23,0.1,,,,,,pending,
+4,0x2c7536E3605D9C16a7a3D7b1898e529396a65c23,,,0x0000000000000000000000000000000000000001,5,7,0x01,mined,
+`, buf.String())
+}
+
+// go test -count=1 -v met/transaction -run 'TestCheckAddress'
+func TestCheckAddress(t *testing.T) {
+	_, err := CheckAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+	assert.NoError(t, err)
+	// 全部小写或大写时没有checksum
+	_, err = CheckAddress("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23")
+	assert.NoError(t, err)
+	_, err = CheckAddress("0x2C7536E3605D9C16A7A3D7B1898E529396A65C23")
+	assert.NoError(t, err)
+
+	_, err = CheckAddress("0x2C7536E3605D9c16a7a3D7b1898e529396a65c23")
+	assert.ErrorContains(t, err, "checksum")
+	_, err = CheckAddress("0x2c75")
+	assert.Error(t, err)
+}
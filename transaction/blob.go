@@ -0,0 +1,108 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"met/utils"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// 每个field element 32字节，第一个字节为0保证小于BLS modulus，剩余31字节保存数据
+	blobFieldElements   = 4096
+	blobBytesPerElement = 31
+	// BlobDataSize 一个blob最多保存的数据长度
+	BlobDataSize = blobFieldElements * blobBytesPerElement
+)
+
+// EncodeBlob 把数据按每个field element 31字节写入blob，不足的部分补0
+func EncodeBlob(data []byte) (*kzg4844.Blob, error) {
+	if len(data) > BlobDataSize {
+		return nil, fmt.Errorf("data size: %v exceeds blob capacity: %v", len(data), BlobDataSize)
+	}
+
+	var blob kzg4844.Blob
+	for i := 0; i*blobBytesPerElement < len(data); i++ {
+		end := min((i+1)*blobBytesPerElement, len(data))
+		copy(blob[i*32+1:], data[i*blobBytesPerElement:end])
+	}
+	return &blob, nil
+}
+
+// BuildBlobSidecar 在本地计算每个blob的kzg commitment和proof
+// 每个交易最多的blob数量随分叉变化，由节点检查
+func BuildBlobSidecar(datas [][]byte) (*types.BlobTxSidecar, error) {
+	if len(datas) == 0 {
+		return nil, errors.New("no blob data")
+	}
+
+	sidecar := &types.BlobTxSidecar{}
+	for i, data := range datas {
+		blob, err := EncodeBlob(data)
+		if err != nil {
+			return nil, fmt.Errorf("blob #%d: %w", i, err)
+		}
+		commitment, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return nil, fmt.Errorf("blob #%d: compute commitment error: %w", i, err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+		if err != nil {
+			return nil, fmt.Errorf("blob #%d: compute proof error: %w", i, err)
+		}
+		sidecar.Blobs = append(sidecar.Blobs, *blob)
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+	return sidecar, nil
+}
+
+// ReadBlobFiles 每个文件作为一个blob
+func ReadBlobFiles(paths []string) (*types.BlobTxSidecar, error) {
+	logger := utils.GetLogger("ReadBlobFiles")
+
+	var datas [][]byte
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read blob file error: %w", err)
+		}
+		if len(data) > BlobDataSize {
+			return nil, fmt.Errorf("blob file: %v size: %v exceeds blob capacity: %v", path, len(data), BlobDataSize)
+		}
+		logger.Debug().Msgf("blob file: %v size: %v", path, len(data))
+		datas = append(datas, data)
+	}
+
+	logger.Info().Msgf("compute kzg commitments and proofs of %v blobs..", len(datas))
+	return BuildBlobSidecar(datas)
+}
+
+// EstimateBlobFeeCap 使用eth_blobBaseFee查询下一个区块的blob base fee，和baseFee一样预留2倍
+// blob base fee的计算参数随分叉变化，由节点按链配置计算
+func EstimateBlobFeeCap(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+	logger := utils.GetLogger("EstimateBlobFeeCap")
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get latest block header error: %w", err)
+	}
+	if header.ExcessBlobGas == nil {
+		return nil, errors.New("chain does not support blob tx: no excessBlobGas in latest block (eip4844)")
+	}
+
+	var blobBaseFee hexutil.Big
+	err = client.Client().CallContext(ctx, &blobBaseFee, "eth_blobBaseFee")
+	if err != nil {
+		return nil, fmt.Errorf("get blob base fee error: %w", err)
+	}
+	logger.Debug().Msgf("excessBlobGas: %v blobBaseFee: %v", *header.ExcessBlobGas, blobBaseFee.ToInt())
+	return new(big.Int).Mul(blobBaseFee.ToInt(), big.NewInt(2)), nil
+}
@@ -0,0 +1,38 @@
+package transaction
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestBlobSidecar'
+func TestBlobSidecar(t *testing.T) {
+	data := bytes.Repeat([]byte{0xff}, 40)
+	blob, err := EncodeBlob(data)
+	assert.NoError(t, err)
+	// 每个field element第一个字节为0，保存31字节数据
+	assert.Equal(t, byte(0), blob[0])
+	assert.Equal(t, data[:31], blob[1:32])
+	assert.Equal(t, byte(0), blob[32])
+	assert.Equal(t, data[31:], blob[33:42])
+	assert.Equal(t, make([]byte, len(blob)-42), blob[42:])
+
+	_, err = EncodeBlob(make([]byte, BlobDataSize+1))
+	assert.Error(t, err)
+
+	sidecar, err := BuildBlobSidecar([][]byte{data, []byte("met")})
+	assert.NoError(t, err)
+	assert.Len(t, sidecar.Blobs, 2)
+	for i := range sidecar.Blobs {
+		assert.NoError(t, kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]))
+	}
+	hashes := sidecar.BlobHashes()
+	assert.Len(t, hashes, 2)
+	assert.True(t, kzg4844.IsValidVersionedHash(hashes[0][:]))
+
+	_, err = BuildBlobSidecar(nil)
+	assert.Error(t, err)
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"met/database"
 	"met/utils"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-// 等待指定的区块高度到来
-func WaitBlock(client *ethclient.Client, height string, heightInterval, heightTimeout uint) error {
+const (
+	// heightTimeout为0并且network没有设置receiptTimeout时，等待区块高度的超时时间
+	DefaultHeightTimeout = 600 * time.Second
+)
+
+// 等待指定的区块高度到来，超时后不返回错误
+// heightTimeout为0时使用network的receiptTimeout，都没有设置时使用DefaultHeightTimeout
+func WaitBlock(client *ethclient.Client, net *database.Network, height string, heightInterval, heightTimeout uint) error {
 	logger := utils.GetLogger("WaitBlock")
 	if height == "" {
 		logger.Info().Msgf("height is empty, do not wait")
@@ -19,7 +26,6 @@ func WaitBlock(client *ethclient.Client, height string, heightInterval, heightTi
 	}
 
 	logger.Debug().Msgf("check block height interval: %v ms", heightInterval)
-	logger.Debug().Msgf("check block height timeout: %v s", heightTimeout)
 
 	blockHeight, ok := new(big.Int).SetString(height, 10)
 	if !ok {
@@ -28,30 +34,25 @@ func WaitBlock(client *ethclient.Client, height string, heightInterval, heightTi
 	}
 
 	// 超时时间设置，单位秒
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(heightTimeout))
-	defer cancel()
-
-	// 定时器间隔设置，单位毫秒
-	ticker := time.NewTicker(time.Millisecond * time.Duration(heightInterval))
-	defer ticker.Stop()
-
-OUTTER:
-	for {
-		header, err := client.HeaderByNumber(ctx, nil)
-		if err != nil {
-			logger.Error().Err(err).Msgf("get latest block")
-		} else {
-			logger.Debug().Msgf("waiting for target: %v, current: %v", blockHeight.String(), header.Number.String())
-			if header.Number.Cmp(blockHeight) >= 0 {
-				logger.Info().Msgf("block meet")
-				break
-			}
+	timeout := time.Second * time.Duration(heightTimeout)
+	if heightTimeout == 0 {
+		timeout = DefaultHeightTimeout
+		if net.Defaults.ReceiptTimeout != 0 {
+			timeout = net.ReceiptTimeout()
 		}
+	}
+	logger.Debug().Msgf("check block height timeout: %v", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-		select {
-		case <-ticker.C:
-		case <-ctx.Done():
-			break OUTTER
+	// 支持订阅时等待newHeads，否则按interval轮询，单位毫秒
+	heads := WatchHeads(ctx, client, time.Millisecond*time.Duration(heightInterval))
+	for head := range heads {
+		logger.Debug().Msgf("waiting for target: %v, current: %v", blockHeight.String(), head.String())
+		if head.Cmp(blockHeight) >= 0 {
+			logger.Info().Msgf("block meet")
+			break
 		}
 	}
 	return nil
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"met/database"
 	"met/utils"
 	"strconv"
 	"strings"
@@ -16,24 +17,50 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/holiman/uint256"
 	"github.com/shopspring/decimal"
 )
 
-func BuildTx(client *ethclient.Client, from string, to string, value *string, data []byte, ledger bool, gasMode mTypes.GasMode, nonce, chainId, gasLimit, gasLimitRatio, gasRatio, gasPrice, gasTipCap, gasFeeCap string, sendAll bool) (tx *types.Transaction, err error) {
+// BuildTx gasMode为0、gasRatio为空时使用network的默认值，gasPrice和gasFeeCap不能超过network的maxFee
+// accessList为auto时使用eth_createAccessList生成并且只在节省gas时使用，否则为access list的json文件
+// txType为TxTypeUnspecified时由gasMode决定: legacy为type 1，eip1559为type 2，ledger为type 0
+// sidecar不为空时构建type 3的blob交易，maxFeePerBlobGas使用最新区块的excessBlobGas估算
+func BuildTx(client *ethclient.Client, net *database.Network, from string, to string, value *string, data []byte, ledger bool, gasMode mTypes.GasMode, txType mTypes.TxType, nonce, chainId, gasLimit, gasLimitRatio, gasRatio, gasPrice, gasTipCap, gasFeeCap string, sendAll bool, accessList string, sidecar *types.BlobTxSidecar) (tx *types.Transaction, err error) {
 	var (
-		nonce0     uint64
-		gasLimit0  uint64
-		chainId0   *big.Int
-		value0     *big.Int
-		gasFeeCap0 *big.Int
-		gasTipCap0 *big.Int
-		gasPrice0  *big.Int
-		ok         bool
-		logger     = utils.GetLogger("BuildTx")
+		accessList0 types.AccessList
+		blobFeeCap0 *big.Int
+		blobHashes  []common.Hash
+		nonce0      uint64
+		gasLimit0   uint64
+		chainId0    *big.Int
+		value0      *big.Int
+		gasFeeCap0  *big.Int
+		gasTipCap0  *big.Int
+		gasPrice0   *big.Int
+		ok          bool
+		logger      = utils.GetLogger("BuildTx")
 	)
-	ctx, cancel := utils.DefaultTimeoutContext()
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
 	defer cancel()
 
+	// 0. network defaults
+	if gasMode == 0 {
+		gasMode, err = mTypes.ParseGasMode(net.Defaults.GasMode)
+		if err != nil {
+			return nil, fmt.Errorf("network: %v default %w", net.Name, err)
+		}
+		if gasMode == 0 {
+			gasMode = mTypes.GasModeAuto
+		} else {
+			logger.Debug().Msgf("use network default gas mode: %v", net.Defaults.GasMode)
+		}
+	}
+	// 指定了gas价格时不使用默认的gasRatio
+	if gasRatio == "" && gasPrice == "" && gasTipCap == "" && gasFeeCap == "" && net.Defaults.GasRatio != "" {
+		gasRatio = net.Defaults.GasRatio
+		logger.Debug().Msgf("use network default gasRatio: %v", gasRatio)
+	}
+
 	// 1. check flags
 	if gasLimit != "" && gasLimitRatio != "" {
 		return nil, errors.New("gasLimit conflicts with gasLimitRatio")
@@ -72,6 +99,50 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 		if len(data) > 0 {
 			return nil, fmt.Errorf("sendAll do not support input data")
 		}
+		if accessList != "" {
+			return nil, fmt.Errorf("sendAll do not support access list")
+		}
+	}
+
+	if ledger && accessList != "" {
+		return nil, errors.New("ledger only supports legacy tx without access list")
+	}
+
+	if sidecar != nil && txType == mTypes.TxTypeUnspecified {
+		txType = mTypes.TxTypeBlob
+	}
+	if sidecar != nil && txType != mTypes.TxTypeBlob {
+		return nil, fmt.Errorf("blobs only work with tx type 3, got: %v", txType)
+	}
+
+	switch txType {
+	case mTypes.TxTypeUnspecified:
+	case mTypes.TxTypeLegacy, mTypes.TxTypeAccessList, mTypes.TxTypeDynamicFee, mTypes.TxTypeBlob:
+		if ledger && txType != mTypes.TxTypeLegacy {
+			return nil, fmt.Errorf("ledger only supports tx type 0, got: %v", txType)
+		}
+		if txType == mTypes.TxTypeLegacy && accessList != "" {
+			return nil, errors.New("tx type 0 does not support access list")
+		}
+		// type 0和type 1只有gasPrice，自动模式下使用legacy的gas价格
+		if (txType == mTypes.TxTypeLegacy || txType == mTypes.TxTypeAccessList) && gasMode == mTypes.GasModeEip1559 {
+			return nil, fmt.Errorf("tx type %v does not support eip1559 gas mode, use legacy or auto", txType)
+		}
+		if txType == mTypes.TxTypeBlob {
+			if sidecar == nil {
+				return nil, errors.New("tx type 3 (blob) needs blobs, use --blob-file")
+			}
+			if to == "" {
+				return nil, errors.New("blob tx can not create contract")
+			}
+			if sendAll {
+				return nil, errors.New("sendAll do not support blob tx")
+			}
+		}
+	case mTypes.TxTypeSetCode:
+		return nil, errors.New("tx type 4 (eip7702 set code) is not supported by the go-ethereum version met is built with")
+	default:
+		return nil, fmt.Errorf("invalid tx type: %v", txType)
 	}
 
 	// 2. init vars
@@ -103,14 +174,8 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 		if err != nil {
 			return nil, fmt.Errorf("parse nonce: %v error: %w", nonce, err)
 		}
-	} else {
-		logger.Debug().Msgf("query nonce..")
-		nonce0, err = client.PendingNonceAt(ctx, fromAddress)
-		if err != nil {
-			return nil, fmt.Errorf("query nonce error: %w", err)
-		}
+		logger.Debug().Msgf("nonce: %v", nonce0)
 	}
-	logger.Debug().Msgf("nonce: %v", nonce0)
 
 	// chainId
 	if chainId != "" {
@@ -128,6 +193,30 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 	}
 	logger.Debug().Msgf("chainId: %v", chainId0.String())
 
+	// accessList
+	if accessList != "" {
+		logger.Debug().Msgf("resolve access list: %v", accessList)
+		accessList0, err = ResolveAccessList(ctx, client, ethereum.CallMsg{
+			From:  fromAddress,
+			To:    toAddress,
+			Value: value0,
+			Data:  data,
+		}, accessList)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// blob
+	if sidecar != nil {
+		blobHashes = sidecar.BlobHashes()
+		blobFeeCap0, err = EstimateBlobFeeCap(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug().Msgf("blobs: %v blobFeeCap: %v", len(blobHashes), blobFeeCap0)
+	}
+
 	// gasLimit
 	if gasLimit != "" {
 		logger.Debug().Msgf("parse gasLimit: %v", gasLimit)
@@ -137,13 +226,17 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 		}
 	} else {
 		gasLimit0, err = client.EstimateGas(ctx, ethereum.CallMsg{
-			From:  fromAddress,
-			To:    toAddress,
-			Value: value0,
-			Data:  data,
+			From:       fromAddress,
+			To:         toAddress,
+			Value:      value0,
+			Data:       data,
+			AccessList: accessList0,
+			// 合约可以通过BLOBHASH读取blob的versioned hash
+			BlobGasFeeCap: blobFeeCap0,
+			BlobHashes:    blobHashes,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("estimate gas error: %w", err)
+			return nil, fmt.Errorf("estimate gas error: %w", DecodeRevertError(err, builtinAbis()))
 		}
 	}
 	logger.Debug().Msgf("gasLimit: %v", gasLimit0)
@@ -166,6 +259,20 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 		gasMode = mTypes.GasModeLegacy
 	}
 
+	// 指定了交易类型时检查链是否支持，自动模式下使用交易类型对应的gas价格
+	if txType != mTypes.TxTypeUnspecified {
+		if err = checkTxType(ctx, client, txType); err != nil {
+			return nil, err
+		}
+		if gasMode == mTypes.GasModeAuto {
+			if txType == mTypes.TxTypeDynamicFee || txType == mTypes.TxTypeBlob {
+				gasMode = mTypes.GasModeEip1559
+			} else {
+				gasMode = mTypes.GasModeLegacy
+			}
+		}
+	}
+
 	// gas
 	if gasMode == mTypes.GasModeAuto {
 		logger.Info().Msgf("Gas mode: auto")
@@ -314,8 +421,35 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 		logger.Debug().Msgf("after gasRatio, gasTipCap: %v", gasTipCap0.String())
 	}
 
-	// ledger 只支持 legacy tx
-	if ledger {
+	if net.Defaults.MaxFee != "" {
+		gasPrice0, gasFeeCap0, err = capFee(net, gasPrice0, gasTipCap0, gasFeeCap0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 最后分配nonce，之前的步骤出错时不会浪费nonce，SendTx没有广播交易时释放
+	if nonce == "" {
+		nonce0, err = AllocateNonce(ctx, client, net.Name, fromAddress)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug().Msgf("nonce: %v", nonce0)
+	}
+
+	if txType == mTypes.TxTypeUnspecified {
+		switch {
+		case ledger:
+			txType = mTypes.TxTypeLegacy
+		case gasMode == mTypes.GasModeLegacy:
+			txType = mTypes.TxTypeAccessList
+		default:
+			txType = mTypes.TxTypeDynamicFee
+		}
+	}
+
+	switch txType {
+	case mTypes.TxTypeLegacy:
 		logger.Info().Msgf("Transaction type: legacy")
 		tx = types.NewTx(&types.LegacyTx{
 			Nonce:    nonce0,
@@ -325,37 +459,84 @@ func BuildTx(client *ethclient.Client, from string, to string, value *string, da
 			Value:    value0,
 			Data:     data,
 		})
-	} else {
+	case mTypes.TxTypeAccessList:
+		logger.Info().Msgf("Transaction type: accessList")
+		tx = types.NewTx(&types.AccessListTx{
+			ChainID:    chainId0,
+			Nonce:      nonce0,
+			GasPrice:   gasPrice0,
+			Gas:        gasLimit0,
+			To:         toAddress,
+			Value:      value0,
+			Data:       data,
+			AccessList: accessList0,
+		})
+	case mTypes.TxTypeDynamicFee:
+		// legacy gas mode时tipCap和feeCap都使用gasPrice，最多支付gasPrice
 		if gasMode == mTypes.GasModeLegacy {
-			logger.Info().Msgf("Transaction type: accessList")
-			tx = types.NewTx(&types.AccessListTx{
-				ChainID:    chainId0,
-				Nonce:      nonce0,
-				GasPrice:   gasPrice0,
-				Gas:        gasLimit0,
-				To:         toAddress,
-				Value:      value0,
-				Data:       data,
-				AccessList: []types.AccessTuple{},
-			})
-		} else if gasMode == mTypes.GasModeEip1559 {
-			logger.Info().Msgf("Transaction type: dynamicFee (eip1559)")
-			tx = types.NewTx(&types.DynamicFeeTx{
-				ChainID:   chainId0,
-				Nonce:     nonce0,
-				GasTipCap: gasTipCap0,
-				GasFeeCap: gasFeeCap0,
-				Gas:       gasLimit0,
-				To:        toAddress,
-				Value:     value0,
-				Data:      data,
-			})
+			gasTipCap0, gasFeeCap0 = gasPrice0, gasPrice0
 		}
+		logger.Info().Msgf("Transaction type: dynamicFee (eip1559)")
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainId0,
+			Nonce:      nonce0,
+			GasTipCap:  gasTipCap0,
+			GasFeeCap:  gasFeeCap0,
+			Gas:        gasLimit0,
+			To:         toAddress,
+			Value:      value0,
+			Data:       data,
+			AccessList: accessList0,
+		})
+	case mTypes.TxTypeBlob:
+		if gasMode == mTypes.GasModeLegacy {
+			gasTipCap0, gasFeeCap0 = gasPrice0, gasPrice0
+		}
+		if value0 == nil {
+			value0 = new(big.Int)
+		}
+		logger.Info().Msgf("Transaction type: blob (eip4844)")
+		tx = types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainId0),
+			Nonce:      nonce0,
+			GasTipCap:  uint256.MustFromBig(gasTipCap0),
+			GasFeeCap:  uint256.MustFromBig(gasFeeCap0),
+			Gas:        gasLimit0,
+			To:         *toAddress,
+			Value:      uint256.MustFromBig(value0),
+			Data:       data,
+			AccessList: accessList0,
+			BlobFeeCap: uint256.MustFromBig(blobFeeCap0),
+			BlobHashes: blobHashes,
+			Sidecar:    sidecar,
+		})
 	}
 
 	return
 }
 
+// capFee gasPrice超过maxFee时返回错误，gasFeeCap超过maxFee时降低到maxFee
+func capFee(net *database.Network, gasPrice, gasTipCap, gasFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	logger := utils.GetLogger("capFee")
+
+	maxFee, err := utils.ParseUnits(net.Defaults.MaxFee, utils.UnitGwei)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse network: %v maxFee: %v error: %w", net.Name, net.Defaults.MaxFee, err)
+	}
+
+	if gasPrice != nil && gasPrice.Cmp(maxFee) > 0 {
+		return nil, nil, fmt.Errorf("gasPrice: %v exceeds network: %v maxFee: %v gwei", gasPrice, net.Name, net.Defaults.MaxFee)
+	}
+	if gasTipCap != nil && gasTipCap.Cmp(maxFee) > 0 {
+		return nil, nil, fmt.Errorf("gasTipCap: %v exceeds network: %v maxFee: %v gwei", gasTipCap, net.Name, net.Defaults.MaxFee)
+	}
+	if gasFeeCap != nil && gasFeeCap.Cmp(maxFee) > 0 {
+		logger.Warn().Msgf("gasFeeCap: %v exceeds maxFee, use maxFee: %v", gasFeeCap, maxFee)
+		gasFeeCap = maxFee
+	}
+	return gasPrice, gasFeeCap, nil
+}
+
 // @param from  from address of tx
 // @param to    to address of tx
 // @param value amount of eth to be transfered, unit: ETH
@@ -448,7 +629,7 @@ func BuildTransaction(ctx context.Context, client *ethclient.Client, from string
 			Data:  data0,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("estimate gas error: %w", err)
+			return nil, fmt.Errorf("estimate gas error: %w", DecodeRevertError(err, builtinAbis()))
 		}
 	}
 
@@ -600,7 +781,7 @@ type TxParams struct {
 }
 
 // chainId: 为空时从rpc读取
-// nonce：为空时从rpc读取
+// nonce：为空时返回nil，发送时由TransactWithNonce从数据库分配
 // value: 单位是ETH，不为空时转换成wei，
 
 // gasLimitRatio: 不为空时，把estimateGas的结果乘以这个比例
@@ -667,16 +848,7 @@ func GetTxParams(ctx context.Context, client *ethclient.Client, fromAddress, con
 	}
 
 	// Nonce
-	if nonce == "" {
-		from := common.HexToAddress(fromAddress)
-		logger.Debug().Msgf("query nonce for address: %v", from.Hex())
-		n, err := client.PendingNonceAt(ctx, from)
-		if err != nil {
-			return nil, fmt.Errorf("query nonce of address: %v error: %w", fromAddress, err)
-		}
-		logger.Debug().Msgf("nonce: %v", n)
-		txParam.Nonce = big.NewInt(int64(n))
-	} else {
+	if nonce != "" {
 		logger.Debug().Msgf("parse nonce: %v", nonce)
 		n, err := strconv.ParseUint(nonce, 10, 64)
 		if err != nil {
@@ -705,7 +877,7 @@ func GetTxParams(ctx context.Context, client *ethclient.Client, fromAddress, con
 		}
 		estimatedGas, err := client.EstimateGas(ctx, msg)
 		if err != nil {
-			return nil, fmt.Errorf("estimate gas error: %v", err)
+			return nil, fmt.Errorf("estimate gas error: %w", DecodeRevertError(err, builtinAbis()))
 		}
 
 		txParam.GasLimit = estimatedGas
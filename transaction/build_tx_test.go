@@ -0,0 +1,80 @@
+package transaction
+
+import (
+	"math/big"
+	"met/database"
+	mTypes "met/types"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEth 只实现BuildTx查询gas价格用到的方法，最新区块支持eip1559和eip4844
+type fakeEth struct{}
+
+func (fakeEth) GetBlockByNumber(number string, full bool) *types.Header {
+	excessBlobGas := uint64(0)
+	return &types.Header{
+		Number:        big.NewInt(1),
+		Difficulty:    new(big.Int),
+		BaseFee:       big.NewInt(1e9),
+		ExcessBlobGas: &excessBlobGas,
+	}
+}
+
+func (fakeEth) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1e8))
+}
+
+func (fakeEth) BlobBaseFee() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(3))
+}
+
+func fakeEthClient(t *testing.T) *ethclient.Client {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", fakeEth{}))
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return client
+}
+
+// go test -count=1 -v met/transaction -run 'TestBuildBlobTx'
+func TestBuildBlobTx(t *testing.T) {
+	client := fakeEthClient(t)
+	sidecar, err := BuildBlobSidecar([][]byte{[]byte("met")})
+	assert.NoError(t, err)
+
+	const from, to = "0x41BB7A889F20b71E6AaBa492298041E84691C41B", "0x000000000000000000000000000000000000dEaD"
+	build := func(net *database.Network, gasMode mTypes.GasMode) (*types.Transaction, error) {
+		value := ""
+		return BuildTx(client, net, from, to, &value, nil, false, gasMode, mTypes.TxTypeUnspecified, "0", "1337", "21000", "", "", "", "", "", false, "", sidecar)
+	}
+
+	// --gasMode eip1559
+	tx, err := build(&database.Network{Name: "dev"}, mTypes.GasModeEip1559)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.BlobTxType), tx.Type())
+	assert.Equal(t, big.NewInt(1e8), tx.GasTipCap())
+	assert.Equal(t, big.NewInt(1e8+2e9), tx.GasFeeCap())
+	assert.Len(t, tx.BlobHashes(), 1)
+	// eth_blobBaseFee的2倍
+	assert.Equal(t, big.NewInt(6), tx.BlobGasFeeCap())
+
+	// network默认的gas mode为eip1559
+	tx, err = build(&database.Network{Name: "dev", Defaults: database.NetworkDefaults{GasMode: "eip1559"}}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.BlobTxType), tx.Type())
+	assert.Equal(t, big.NewInt(1e8+2e9), tx.GasFeeCap())
+
+	// type 1只有gasPrice
+	value := ""
+	_, err = BuildTx(client, &database.Network{Name: "dev"}, from, to, &value, nil, false, mTypes.GasModeEip1559, mTypes.TxTypeAccessList, "0", "1337", "21000", "", "", "", "", "", false, "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"met/database"
+	utils "met/utils"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var (
+	ErrChainIdMismatch = errors.New("chain id mismatch")
+)
+
+// VerifyChainId 查询rpc的eth_chainId并与network保存的chainId比较，不一致时返回ErrChainIdMismatch
+// network没有保存chainId时(旧版本添加的network)，保存第一次查询到的chainId
+func VerifyChainId(ctx context.Context, client *ethclient.Client, net *database.Network) (*big.Int, error) {
+	logger := utils.GetLogger("VerifyChainId")
+
+	logger.Debug().Msg("query chain id")
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get chain id error: %w", err)
+	}
+
+	if net.ChainId == 0 {
+		if !chainId.IsUint64() {
+			return nil, fmt.Errorf("chain id: %v too large", chainId)
+		}
+		logger.Warn().Msgf("network: %v has no stored chain id, save chain id: %v from rpc", net.Name, chainId)
+		err = database.UpdateNetworkChainId(net.Name, chainId.Uint64())
+		if err != nil {
+			return nil, fmt.Errorf("save chain id of network: %v error: %w", net.Name, err)
+		}
+		net.ChainId = chainId.Uint64()
+		return chainId, nil
+	}
+
+	if !chainId.IsUint64() || chainId.Uint64() != net.ChainId {
+		return nil, fmt.Errorf("%w: network: %v stored: %v rpc: %v", ErrChainIdMismatch, net.Name, net.ChainId, chainId)
+	}
+
+	return chainId, nil
+}
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"met/consts"
@@ -40,7 +41,7 @@ func ParseInput(data string, abi string, method string, abiArgs ...string) ([]by
 // 2. decimals: 代币精度,如果为空，则通过rpc查询;如果为了快速构造input，可以传入
 // 3. method: erc20方法
 // 4. abiArgs: erc20方法参数,如果是amount类的参数，其类型为人类可读的数字，需要转换为区块链底层数字
-func ParseErc20Input(client *ethclient.Client, contractAddress string, symbol string, decimals string, method string, abiArgs ...string) ([]byte, error) {
+func ParseErc20Input(ctx context.Context, client *ethclient.Client, contractAddress string, symbol string, decimals string, method string, abiArgs ...string) ([]byte, error) {
 	logger := utils.GetLogger("ParseErc20Input")
 
 	switch method {
@@ -50,7 +51,7 @@ func ParseErc20Input(client *ethclient.Client, contractAddress string, symbol st
 		}
 		to := abiArgs[0]
 		humanAmount := abiArgs[1]
-		symbol, decimals, err := getErc20SymbolAndDecimals(client, contractAddress, symbol, decimals)
+		symbol, decimals, err := getErc20SymbolAndDecimals(ctx, client, contractAddress, symbol, decimals)
 		if err != nil {
 			return nil, fmt.Errorf("getErc20SymbolAndDecimals error: %v", err)
 		}
@@ -99,15 +100,12 @@ Amount:                %s
 }
 
 // getErc20SymbolAndDecimals 获取erc20代币的symbol和decimals, 如果symbol或decimals为空，则通过rpc查询
-func getErc20SymbolAndDecimals(client *ethclient.Client, contractAddress, symbol string, decimals string) (string, string, error) {
+func getErc20SymbolAndDecimals(ctx context.Context, client *ethclient.Client, contractAddress, symbol string, decimals string) (string, string, error) {
 	logger := utils.GetLogger("getErc20SymbolAndDecimals")
 	var (
 		err error
 	)
 
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
 	if symbol == "" {
 		logger.Debug().Msgf("symbol is empty, try to get from contract: %s", contractAddress)
 		// 通过rpc查询
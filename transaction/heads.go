@@ -0,0 +1,143 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"met/utils"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// rpc不支持订阅时查询最新区块的间隔
+	headPollInterval = time.Second
+)
+
+// WatchHeads 返回最新区块高度的channel，先发送当前区块高度，之后每个新区块发送一次
+// ws和ipc使用newHeads订阅，http等不支持订阅时按interval轮询，订阅断开后也改为轮询
+// ctx结束后channel关闭，调用者不再读取时需要取消ctx
+func WatchHeads(ctx context.Context, client *ethclient.Client, interval time.Duration) <-chan *big.Int {
+	logger := utils.GetLogger("WatchHeads")
+	heads := make(chan *big.Int, 1)
+
+	go func() {
+		defer close(heads)
+
+		headers := make(chan *types.Header, 16)
+		sub, err := client.SubscribeNewHead(ctx, headers)
+		if err != nil {
+			logger.Debug().Msgf("subscribe newHeads error: %v, polling every %v", err, interval)
+			pollHeads(ctx, client, interval, heads)
+			return
+		}
+		defer sub.Unsubscribe()
+		logger.Debug().Msgf("subscribed newHeads")
+
+		// 订阅之后再查询当前区块，不会漏掉两者之间的区块
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			logger.Error().Err(err).Msgf("get latest block")
+		} else if !sendHead(ctx, heads, header.Number) {
+			return
+		}
+
+		for {
+			select {
+			case header := <-headers:
+				if !sendHead(ctx, heads, header.Number) {
+					return
+				}
+			case err := <-sub.Err():
+				logger.Warn().Msgf("newHeads subscription error: %v, fall back to polling", err)
+				pollHeads(ctx, client, interval, heads)
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return heads
+}
+
+// pollHeads 按interval查询最新区块，区块高度变化时才发送
+func pollHeads(ctx context.Context, client *ethclient.Client, interval time.Duration, heads chan<- *big.Int) {
+	logger := utils.GetLogger("pollHeads")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *big.Int
+	for {
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error().Err(err).Msgf("get latest block")
+		} else if last == nil || header.Number.Cmp(last) != 0 {
+			last = header.Number
+			if !sendHead(ctx, heads, header.Number) {
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func sendHead(ctx context.Context, heads chan<- *big.Int, number *big.Int) bool {
+	select {
+	case heads <- number:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// WaitReceipt 每个新区块查询一次交易回执，直到查到回执或者ctx结束
+func WaitReceipt(ctx context.Context, client *ethclient.Client, txHash common.Hash) (*types.Receipt, error) {
+	logger := utils.GetLogger("WaitReceipt")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for head := range WatchHeads(ctx, client, headPollInterval) {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			logger.Debug().Msgf("query receipt at block: %v error: %v", head, err)
+		} else {
+			logger.Debug().Msgf("receipt not yet available at block: %v", head)
+		}
+	}
+	return nil, ctx.Err()
+}
+
+// WaitConfirmations 等待区块blockNumber之后有confirmations个区块
+func WaitConfirmations(ctx context.Context, client *ethclient.Client, blockNumber *big.Int, confirmations uint64) error {
+	logger := utils.GetLogger("WaitConfirmations")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	target := new(big.Int).Add(blockNumber, new(big.Int).SetUint64(confirmations))
+	for head := range WatchHeads(ctx, client, headPollInterval) {
+		logger.Debug().Msgf("diff block number: %v (latest: %v mined: %v)", new(big.Int).Sub(head, blockNumber), head, blockNumber)
+		if head.Cmp(target) >= 0 {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,143 @@
+package transaction
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"met/database"
+	"met/utils"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// RecordTransaction 把广播成功的交易保存到transactions表，用于tx history和tx sync
+// 外部签名的交易(offsign)没有accountName
+func RecordTransaction(accountName string, accountIndex uint, networkName string, tx *types.Transaction) {
+	logger := utils.GetLogger("RecordTransaction")
+
+	record, err := transactionRecord(accountName, accountIndex, networkName, tx)
+	if err == nil {
+		err = database.AddTransaction(record)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msgf("record tx: %v", tx.Hash())
+	}
+}
+
+func transactionRecord(accountName string, accountIndex uint, networkName string, tx *types.Transaction) (*database.Transaction, error) {
+	from, err := TxSender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover sender error: %w", err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("marshal tx error: %w", err)
+	}
+
+	var to string
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
+	return &database.Transaction{
+		Hash:         tx.Hash().Hex(),
+		Network:      networkName,
+		ChainId:      tx.ChainId().String(),
+		AccountName:  accountName,
+		AccountIndex: accountIndex,
+		From:         from.Hex(),
+		To:           to,
+		Nonce:        tx.Nonce(),
+		Value:        tx.Value().String(),
+		Type:         tx.Type(),
+		Summary:      CallSummary(tx),
+		Raw:          "0x" + hex.EncodeToString(raw),
+		Status:       database.TxStatusPending,
+	}, nil
+}
+
+// RecordReceipt 交易上链后更新transactions表中的状态
+func RecordReceipt(receipt *types.Receipt) {
+	logger := utils.GetLogger("RecordReceipt")
+
+	tx := database.Transaction{Hash: receipt.TxHash.Hex()}
+	setReceipt(&tx, receipt)
+	if err := database.UpdateTransactionStatus(&tx); err != nil {
+		logger.Error().Err(err).Msgf("record receipt of tx: %v", receipt.TxHash)
+	}
+}
+
+func setReceipt(tx *database.Transaction, receipt *types.Receipt) {
+	tx.Status = database.TxStatusMined
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		tx.Status = database.TxStatusFailed
+	}
+	tx.BlockNumber = receipt.BlockNumber.Uint64()
+	tx.GasUsed = receipt.GasUsed
+}
+
+// SyncTransactions 更新pending交易的状态，返回状态有变化的交易
+// 先查询所有交易的回执，再判断没有回执的交易是被替换还是被丢弃，这样替换它的交易已经是mined状态
+func SyncTransactions(ctx context.Context, client *ethclient.Client, txs []database.Transaction) ([]database.Transaction, error) {
+	logger := utils.GetLogger("SyncTransactions")
+
+	var (
+		updated []database.Transaction
+		waiting []database.Transaction
+	)
+	for _, tx := range txs {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(tx.Hash))
+		if errors.Is(err, ethereum.NotFound) {
+			waiting = append(waiting, tx)
+			continue
+		}
+		if err != nil {
+			return updated, fmt.Errorf("query receipt: %v error: %w", tx.Hash, err)
+		}
+
+		setReceipt(&tx, receipt)
+		if err := database.UpdateTransactionStatus(&tx); err != nil {
+			return updated, err
+		}
+		updated = append(updated, tx)
+	}
+
+	for _, tx := range waiting {
+		_, _, err := client.TransactionByHash(ctx, common.HexToHash(tx.Hash))
+		if err == nil {
+			logger.Debug().Msgf("tx: %v still in pool", tx.Hash)
+			continue
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return updated, fmt.Errorf("query tx: %v error: %w", tx.Hash, err)
+		}
+
+		latest, err := client.NonceAt(ctx, common.HexToAddress(tx.From), nil)
+		if err != nil {
+			return updated, fmt.Errorf("query nonce of: %v error: %w", tx.From, err)
+		}
+
+		if latest > tx.Nonce {
+			tx.Status = database.TxStatusReplaced
+			replacement, err := database.QueryMinedTransactionByNonce(tx.Network, tx.From, tx.Nonce)
+			if err != nil {
+				return updated, err
+			}
+			if replacement != nil {
+				tx.ReplacedBy = replacement.Hash
+			}
+		} else {
+			tx.Status = database.TxStatusDropped
+		}
+		if err := database.UpdateTransactionStatus(&tx); err != nil {
+			return updated, err
+		}
+		updated = append(updated, tx)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,168 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"met/consts"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxTypeName 交易类型的名称，eg: 2 (eip1559)
+func TxTypeName(txType uint8) string {
+	switch txType {
+	case types.LegacyTxType:
+		return "0 (legacy)"
+	case types.AccessListTxType:
+		return "1 (access list)"
+	case types.DynamicFeeTxType:
+		return "2 (eip1559)"
+	case types.BlobTxType:
+		return "3 (blob)"
+	default:
+		return fmt.Sprintf("%d (unknown)", txType)
+	}
+}
+
+// ParseTxHash 解析0x开头的32字节交易hash
+func ParseTxHash(s string) (common.Hash, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid tx hash: %v", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// TxSender 从签名中恢复交易的发送者
+func TxSender(tx *types.Transaction) (common.Address, error) {
+	return types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+}
+
+// BuiltinAbiJson 内置abi名称(erc20 erc721 erc1155)转换为abi json，其他原样返回
+func BuiltinAbiJson(name string) string {
+	switch name {
+	case consts.Erc20:
+		return consts.Erc20Abi
+	case consts.Erc721:
+		return consts.Erc721Abi
+	case consts.Erc1155:
+		return consts.Erc1155Abi
+	default:
+		return name
+	}
+}
+
+// DecodeAbis 解析input和日志使用的abi，abiJson不为空时排在内置abi之前
+// abiJson可以是abi json或者内置abi名称
+func DecodeAbis(abiJson string) ([]*abi.ABI, error) {
+	var abis []*abi.ABI
+	if abiJson != "" {
+		abiObj, err := ParseAbiJson(BuiltinAbiJson(abiJson))
+		if err != nil {
+			return nil, fmt.Errorf("parse abi error: %w", err)
+		}
+		abis = append(abis, abiObj)
+	}
+
+	for _, builtin := range []string{consts.Erc20Abi, consts.Erc721Abi, consts.Erc1155Abi} {
+		abiObj, err := ParseAbiJson(builtin)
+		if err != nil {
+			continue
+		}
+		abis = append(abis, abiObj)
+	}
+	return abis, nil
+}
+
+// builtinAbis 内置abi，用于没有提供abi时解析
+func builtinAbis() []*abi.ABI {
+	abis, _ := DecodeAbis("")
+	return abis
+}
+
+// DecodeCallWith 依次使用abis解析input，都无法解析时返回false
+func DecodeCallWith(abis []*abi.ABI, data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	for _, abiObj := range abis {
+		if decoded, ok := decodeCallWithAbi(abiObj, data); ok {
+			return decoded, true
+		}
+	}
+	return "", false
+}
+
+// DecodeLog 依次使用abis解析日志，eg: Transfer(from=0x.., to=0x.., value=100)
+// erc20和erc721的Transfer topic相同，indexed参数数量也必须一致
+func DecodeLog(abis []*abi.ABI, log *types.Log) (string, bool) {
+	if len(log.Topics) == 0 {
+		return "", false
+	}
+	for _, abiObj := range abis {
+		if decoded, ok := decodeLogWithAbi(abiObj, log); ok {
+			return decoded, true
+		}
+	}
+	return "", false
+}
+
+func decodeLogWithAbi(abiObj *abi.ABI, log *types.Log) (string, bool) {
+	event, err := abiObj.EventByID(log.Topics[0])
+	if err != nil {
+		return "", false
+	}
+
+	// 没有参数名时使用argN，解析结果按参数名保存，空参数名会互相覆盖
+	inputs := make(abi.Arguments, len(event.Inputs))
+	for i, input := range event.Inputs {
+		if input.Name == "" {
+			input.Name = fmt.Sprintf("arg%d", i)
+		}
+		inputs[i] = input
+	}
+
+	var indexed abi.Arguments
+	for _, input := range inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(indexed) != len(log.Topics)-1 {
+		return "", false
+	}
+
+	values := make(map[string]any)
+	err = abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:])
+	if err != nil {
+		return "", false
+	}
+	err = inputs.NonIndexed().UnpackIntoMap(values, log.Data)
+	if err != nil {
+		return "", false
+	}
+
+	var argStrs []string
+	for _, input := range inputs {
+		argStrs = append(argStrs, fmt.Sprintf("%s=%s", input.Name, formatArg(values[input.Name])))
+	}
+	return fmt.Sprintf("%s(%s)", event.Name, strings.Join(argStrs, ", ")), true
+}
+
+// formatArg bytes类型显示为hex，其他使用默认格式
+func formatArg(arg any) string {
+	switch v := arg.(type) {
+	case []byte:
+		return fmt.Sprintf("0x%x", v)
+	case [32]byte:
+		return common.Hash(v).Hex()
+	case *big.Int:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,82 @@
+package transaction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestDecodeLog'
+func TestDecodeLog(t *testing.T) {
+	abis, err := DecodeAbis("")
+	assert.NoError(t, err)
+
+	from := common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+	to := common.HexToAddress("0x41BB7A889F20b71E6AaBa492298041E84691C41B")
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// erc20 Transfer: value不是indexed
+	log := &types.Log{
+		Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:   common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+	decoded, ok := DecodeLog(abis, log)
+	assert.True(t, ok)
+	assert.Equal(t, "Transfer(from="+from.Hex()+", to="+to.Hex()+", value=100)", decoded)
+
+	// erc721 Transfer: tokenId是indexed
+	log = &types.Log{
+		Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(7))},
+	}
+	decoded, ok = DecodeLog(abis, log)
+	assert.True(t, ok)
+	assert.Contains(t, decoded, "=7)")
+
+	_, ok = DecodeLog(abis, &types.Log{Topics: []common.Hash{crypto.Keccak256Hash([]byte("Unknown()"))}})
+	assert.False(t, ok)
+
+	data, err := ParseAbi("erc20", "transfer", to.Hex(), "100")
+	assert.NoError(t, err)
+	decoded, ok = DecodeCallWith(abis, data)
+	assert.True(t, ok)
+	assert.Equal(t, "transfer(address,uint256)("+to.Hex()+", 100)", decoded)
+
+	_, err = ParseTxHash("0x12")
+	assert.Error(t, err)
+}
+
+// go test -count=1 -v met/transaction -run 'TestDecodeLogUnnamed'
+func TestDecodeLogUnnamed(t *testing.T) {
+	addressType, err := abi.NewType("address", "", nil)
+	assert.NoError(t, err)
+	uintType, err := abi.NewType("uint256", "", nil)
+	assert.NoError(t, err)
+
+	// 直接构造的event没有经过abi json解析，参数名为空
+	event := abi.Event{
+		Name:    "Deposit",
+		RawName: "Deposit",
+		Inputs: abi.Arguments{
+			{Type: addressType, Indexed: true},
+			{Type: uintType},
+			{Type: uintType},
+		},
+		ID: crypto.Keccak256Hash([]byte("Deposit(address,uint256,uint256)")),
+	}
+	abiObj := &abi.ABI{Events: map[string]abi.Event{event.Name: event}}
+
+	from := common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+	data := append(common.BigToHash(big.NewInt(1)).Bytes(), common.BigToHash(big.NewInt(2)).Bytes()...)
+	log := &types.Log{
+		Topics: []common.Hash{event.ID, common.BytesToHash(from.Bytes())},
+		Data:   data,
+	}
+	decoded, ok := DecodeLog([]*abi.ABI{abiObj}, log)
+	assert.True(t, ok)
+	assert.Equal(t, "Deposit(arg0="+from.Hex()+", arg1=1, arg2=2)", decoded)
+}
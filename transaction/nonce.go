@@ -0,0 +1,151 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"met/database"
+	"met/utils"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gorm.io/gorm"
+)
+
+// AllocateNonce 从数据库分配nonce，多个met进程同时发送交易时不会使用相同的nonce
+// 数据库中的nonce落后于链上的pending nonce时使用链上的值
+func AllocateNonce(ctx context.Context, client *ethclient.Client, network string, from common.Address) (uint64, error) {
+	logger := utils.GetLogger("AllocateNonce")
+
+	pending, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return 0, fmt.Errorf("query nonce error: %w", err)
+	}
+
+	nonce, err := database.AllocateNonce(network, from.Hex(), pending)
+	if err != nil {
+		return 0, fmt.Errorf("allocate nonce error: %w", err)
+	}
+	logger.Debug().Msgf("allocated nonce: %v (pending nonce: %v)", nonce, pending)
+	if nonce > pending {
+		logger.Info().Msgf("nonce: %v is ahead of pending nonce: %v, earlier txs are not yet in the pool", nonce, pending)
+	}
+	return nonce, nil
+}
+
+// recordNonce 交易广播成功时记录nonce已使用，否则allocated为true时释放AllocateNonce分配的nonce
+// 用户指定的nonce可能是其他进程分配的，没有广播时不能释放
+func recordNonce(network, from string, nonce uint64, txHash string, sent bool, allocated bool) {
+	logger := utils.GetLogger("recordNonce")
+
+	address := common.HexToAddress(from).Hex()
+	var err error
+	switch {
+	case sent:
+		err = database.MarkNonceSent(network, address, nonce, txHash)
+	case allocated:
+		err = database.ReleaseNonce(network, address, nonce)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msgf("record nonce: %v of address: %v", nonce, address)
+	}
+}
+
+// TransactWithNonce 使用bind的TransactOpts发送交易，opts.Nonce为nil时从数据库分配nonce
+// transact中广播成功时记录nonce，否则释放分配的nonce
+func TransactWithNonce(ctx context.Context, client *ethclient.Client, network string, opts *bind.TransactOpts, transact func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	logger := utils.GetLogger("TransactWithNonce")
+
+	allocated := opts.Nonce == nil
+	if allocated {
+		nonce, err := AllocateNonce(ctx, client, network, opts.From)
+		if err != nil {
+			return nil, err
+		}
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		logger.Info().Msgf("Nonce: %v", nonce)
+	}
+
+	tx, err := transact(opts)
+	var txHash string
+	if err == nil {
+		txHash = tx.Hash().Hex()
+	}
+	recordNonce(network, opts.From.Hex(), opts.Nonce.Uint64(), txHash, err == nil, allocated)
+	return tx, err
+}
+
+const (
+	// 超过这个时间还没有广播的nonce认为发送进程已经退出，算作空缺
+	staleNonceAllocation = 10 * time.Minute
+)
+
+// NonceStatus 链上和数据库中的nonce
+type NonceStatus struct {
+	// 链上已确认的nonce
+	Latest uint64
+	// 链上交易池中连续的下一个nonce
+	Pending uint64
+	// 数据库中下一个要分配的nonce，没有分配过时为Pending
+	Next uint64
+	// 不小于Latest的分配记录
+	Allocations []database.NonceAllocation
+	// [Pending, Next)中没有在交易池中的nonce，后续交易在它们被使用之前不会上链
+	Gaps []uint64
+}
+
+// GetNonceStatus 比较链上和数据库中的nonce，找出空缺，并删除已经确认的分配记录
+func GetNonceStatus(ctx context.Context, client *ethclient.Client, network string, from common.Address) (*NonceStatus, error) {
+	latest, err := client.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("query nonce error: %w", err)
+	}
+	pending, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("query pending nonce error: %w", err)
+	}
+
+	status := &NonceStatus{Latest: latest, Pending: pending, Next: pending}
+
+	state, err := database.QueryNonce(network, from.Hex())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status, nil
+	} else if err != nil {
+		return nil, err
+	}
+	status.Next = max(state.Next, pending)
+
+	err = database.PruneNonceAllocations(network, from.Hex(), latest)
+	if err != nil {
+		return nil, err
+	}
+	status.Allocations, err = database.QueryNonceAllocations(network, from.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make(map[uint64]database.NonceAllocation)
+	for _, allocation := range status.Allocations {
+		allocations[allocation.Nonce] = allocation
+	}
+	for nonce := pending; nonce < status.Next; nonce++ {
+		allocation, ok := allocations[nonce]
+		if ok && allocation.Status == database.NonceAllocated && time.Since(allocation.UpdatedAt) < staleNonceAllocation {
+			// 其他进程正在发送
+			continue
+		}
+		if ok && allocation.Status == database.NonceSent && allocation.TxHash != "" {
+			_, _, err := client.TransactionByHash(ctx, common.HexToHash(allocation.TxHash))
+			if err == nil {
+				// 在交易池的queued中
+				continue
+			}
+		}
+		status.Gaps = append(status.Gaps, nonce)
+	}
+	return status, nil
+}
@@ -0,0 +1,33 @@
+package transaction
+
+import (
+	"met/database"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestRecordNonce'
+func TestRecordNonce(t *testing.T) {
+	database.InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	const network, address = "eth", "0x41BB7A889F20b71E6AaBa492298041E84691C41B"
+
+	// 其他进程分配的nonce
+	n, err := database.AllocateNonce(network, address, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), n)
+
+	// 用户用--nonce指定了同一个nonce，没有广播时不能释放
+	recordNonce(network, address, n, "", false, false)
+	allocations, err := database.QueryNonceAllocations(network, address)
+	assert.NoError(t, err)
+	assert.Len(t, allocations, 1)
+	assert.Equal(t, database.NonceAllocated, allocations[0].Status)
+
+	recordNonce(network, address, n, "", false, true)
+	allocations, err = database.QueryNonceAllocations(network, address)
+	assert.NoError(t, err)
+	assert.Equal(t, database.NonceReleased, allocations[0].Status)
+}
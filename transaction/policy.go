@@ -0,0 +1,223 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"met/database"
+	utils "met/utils"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPolicyViolation = errors.New("policy violation")
+)
+
+// CheckPolicy 在签名之前检查交易是否满足账户的签名策略
+// 没有策略时直接通过；违反策略时，如果overridePolicy为true，需要重新输入账户密码才能继续
+func CheckPolicy(accountName string, networkName string, tx *types.Transaction, overridePolicy bool) error {
+	return CheckBatchPolicy(accountName, networkName, []*types.Transaction{tx}, overridePolicy)
+}
+
+// CheckBatchPolicy 依次检查多个交易，daily cap按之前交易的value累计，只需要输入一次密码
+func CheckBatchPolicy(accountName string, networkName string, txs []*types.Transaction, overridePolicy bool) error {
+	logger := utils.GetLogger("CheckPolicy")
+
+	policy, err := database.QueryPolicy(accountName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Debug().Msgf("no policy for account: %v", accountName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("query policy of account: %v error: %w", accountName, err)
+	}
+
+	spent := big.NewInt(0)
+	if policy.DailyCap != "" {
+		spends, err := database.QuerySpendsSince(accountName, networkName, time.Now().Add(-24*time.Hour))
+		if err != nil {
+			return fmt.Errorf("query spends of account: %v error: %w", accountName, err)
+		}
+		for _, spend := range spends {
+			v, ok := new(big.Int).SetString(spend.Value, 10)
+			if !ok {
+				return fmt.Errorf("invalid spend value: %v", spend.Value)
+			}
+			spent.Add(spent, v)
+		}
+	}
+
+	var violations []string
+	for i, tx := range txs {
+		txViolations, err := EvaluatePolicy(&policy, spent, tx)
+		if err != nil {
+			return fmt.Errorf("evaluate policy of account: %v error: %w", accountName, err)
+		}
+		for _, violation := range txViolations {
+			if len(txs) > 1 {
+				violation = fmt.Sprintf("tx #%d: %v", i+1, violation)
+			}
+			violations = append(violations, violation)
+		}
+		if tx.Value() != nil {
+			spent.Add(spent, tx.Value())
+		}
+	}
+	if len(violations) == 0 {
+		logger.Debug().Msgf("policy of account: %v passed", accountName)
+		return nil
+	}
+
+	violationErr := fmt.Errorf("%w (account: %v): %v", ErrPolicyViolation, accountName, strings.Join(violations, "; "))
+	if !overridePolicy {
+		return violationErr
+	}
+
+	logger.Warn().Msgf("%v", violationErr)
+	return ConfirmAccountPassword(accountName, "override policy")
+}
+
+// EvaluatePolicy 返回交易违反的所有策略，spent为24小时内已经花费的native value(wei)
+func EvaluatePolicy(policy *database.Policy, spent *big.Int, tx *types.Transaction) ([]string, error) {
+	var violations []string
+
+	value := tx.Value()
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	if policy.MaxValue != "" {
+		maxValue, err := utils.ParseUnits(policy.MaxValue, utils.UnitEth)
+		if err != nil {
+			return nil, fmt.Errorf("parse max value: %v error: %w", policy.MaxValue, err)
+		}
+		if value.Cmp(maxValue) > 0 {
+			violations = append(violations, fmt.Sprintf("value %v wei exceeds max value %v ETH", value, policy.MaxValue))
+		}
+	}
+
+	if policy.DailyCap != "" {
+		dailyCap, err := utils.ParseUnits(policy.DailyCap, utils.UnitEth)
+		if err != nil {
+			return nil, fmt.Errorf("parse daily cap: %v error: %w", policy.DailyCap, err)
+		}
+		total := new(big.Int).Add(spent, value)
+		if total.Cmp(dailyCap) > 0 {
+			violations = append(violations, fmt.Sprintf("24h spend %v wei (including this tx) exceeds daily cap %v ETH", total, policy.DailyCap))
+		}
+	}
+
+	if policy.AllowedTo != "" {
+		if tx.To() == nil {
+			violations = append(violations, "contract creation is not in allowed to list")
+		} else if !containsAddress(policy.AllowedTo, *tx.To()) {
+			violations = append(violations, fmt.Sprintf("to address %v is not in allowed to list", tx.To().Hex()))
+		}
+	}
+
+	if policy.AllowedSelectors != "" && len(tx.Data()) > 0 {
+		if len(tx.Data()) < 4 {
+			violations = append(violations, fmt.Sprintf("input data 0x%x is too short to contain a method selector", tx.Data()))
+		} else {
+			selector := hexutil.Encode(tx.Data()[:4])
+			if !containsSelector(policy.AllowedSelectors, selector) {
+				violations = append(violations, fmt.Sprintf("method selector %v is not in allowed selector list", selector))
+			}
+		}
+	}
+
+	if policy.MaxFeePerGas != "" {
+		maxFee, err := utils.ParseUnits(policy.MaxFeePerGas, utils.UnitGwei)
+		if err != nil {
+			return nil, fmt.Errorf("parse max fee per gas: %v error: %w", policy.MaxFeePerGas, err)
+		}
+		// legacy tx的GasFeeCap即GasPrice
+		if tx.GasFeeCap().Cmp(maxFee) > 0 {
+			violations = append(violations, fmt.Sprintf("fee per gas %v wei exceeds max fee per gas %v gwei", tx.GasFeeCap(), policy.MaxFeePerGas))
+		}
+	}
+
+	return violations, nil
+}
+
+// PolicySigner 包装bind.SignerFn，在签名之前检查策略，用于bind.TransactOpts
+func PolicySigner(accountName string, networkName string, overridePolicy bool, signerFn bind.SignerFn) bind.SignerFn {
+	return func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		err := CheckPolicy(accountName, networkName, tx, overridePolicy)
+		if err != nil {
+			return nil, err
+		}
+		return signerFn(address, tx)
+	}
+}
+
+// RecordSpend 交易发送成功后记录native value，用于daily cap
+func RecordSpend(accountName string, networkName string, tx *types.Transaction) {
+	logger := utils.GetLogger("RecordSpend")
+
+	if tx.Value() == nil || tx.Value().Sign() == 0 {
+		return
+	}
+
+	err := database.AddSpend(&database.Spend{
+		AccountName: accountName,
+		Network:     networkName,
+		Value:       tx.Value().String(),
+		TxHash:      tx.Hash().Hex(),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msgf("record spend of tx: %v", tx.Hash())
+	}
+}
+
+// ConfirmAccountPassword 输入并验证账户密码，用于忽略、修改和删除策略，action用于提示信息
+func ConfirmAccountPassword(accountName string, action string) error {
+	account, err := database.QueryAccount(accountName)
+	if err != nil {
+		return fmt.Errorf("query account: %v error: %w", accountName, err)
+	}
+	if account.PasswordHash == "" {
+		return fmt.Errorf("account: %v has no password, lock it once to set a password before you %v", accountName, action)
+	}
+
+	password, err := utils.ReadSecret(fmt.Sprintf("Enter password of account %v to %v: ", accountName, action))
+	if err != nil {
+		return fmt.Errorf("read password error: %w", err)
+	}
+
+	if !utils.VerifyPassword(password, account.PasswordHash) {
+		return fmt.Errorf("wrong password")
+	}
+
+	return nil
+}
+
+func containsAddress(list string, address common.Address) bool {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" && common.HexToAddress(item) == address {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSelector(list string, selector string) bool {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if !strings.HasPrefix(item, "0x") {
+			item = "0x" + item
+		}
+		if item == selector {
+			return true
+		}
+	}
+	return false
+}
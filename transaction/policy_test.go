@@ -0,0 +1,49 @@
+package transaction
+
+import (
+	"math/big"
+	"met/database"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestEvaluatePolicy'
+func TestEvaluatePolicy(t *testing.T) {
+	to := common.HexToAddress("0x9D757Dd679bE17b4094c740fB0047fa3a7Ed6DF0")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(50_000_000_000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(2_000_000_000_000_000_000),
+		Data:      common.FromHex("0xa9059cbb00"),
+	})
+
+	policy := database.Policy{
+		MaxValue:         "3",
+		DailyCap:         "5",
+		AllowedTo:        "0x9d757dd679be17b4094c740fb0047fa3a7ed6df0",
+		AllowedSelectors: "a9059cbb",
+		MaxFeePerGas:     "100",
+	}
+	violations, err := EvaluatePolicy(&policy, big.NewInt(0), tx)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	policy = database.Policy{
+		MaxValue:         "1",
+		DailyCap:         "2.5",
+		AllowedTo:        "0x41cbC063B4b3264F5a075012e685B9fA05e41a44",
+		AllowedSelectors: "0x095ea7b3",
+		MaxFeePerGas:     "10",
+	}
+	spent, _ := new(big.Int).SetString("1000000000000000000", 10)
+	violations, err = EvaluatePolicy(&policy, spent, tx)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 5)
+	t.Logf("violations: %v", violations)
+}
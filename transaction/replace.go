@@ -0,0 +1,197 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"met/database"
+	"met/utils"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// geth txpool默认要求替换交易的fee至少提高10%
+	MinPriceBump     = 10
+	DefaultPriceBump = "15%"
+)
+
+var (
+	ErrReplacedByUnknown = errors.New("nonce is used by an unknown tx")
+)
+
+// ParsePriceBump 解析fee涨幅，eg: 15% 15
+func ParsePriceBump(bump string) (int64, error) {
+	percent, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(bump), "%"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bump: %v", bump)
+	}
+	if percent < MinPriceBump {
+		return 0, fmt.Errorf("bump: %v is below the replacement threshold of most nodes: %v%%", bump, MinPriceBump)
+	}
+	return percent, nil
+}
+
+// BuildReplacement 使用原交易的nonce构建替换交易，fee在原交易基础上提高percent，低于当前建议值时使用建议值
+// cancel为false时保持原交易的to value data gas和access list，为true时构建发给自己的0 value交易
+// ledger只能签名legacy交易，此时使用gasPrice不低于提高后gasFeeCap的legacy交易替换
+func BuildReplacement(ctx context.Context, client *ethclient.Client, old *types.Transaction, from common.Address, cancel bool, percent int64, ledger bool) (*types.Transaction, error) {
+	logger := utils.GetLogger("BuildReplacement")
+
+	to, value, data, gas, accessList := old.To(), old.Value(), old.Data(), old.Gas(), old.AccessList()
+	if cancel {
+		to, value, data, gas, accessList = &from, big.NewInt(0), nil, OnlyTransferGas, nil
+	}
+
+	var gasPrice, gasTipCap, gasFeeCap *big.Int
+	switch old.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		suggested, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("query gasPrice error: %w", err)
+		}
+		gasPrice = bigMax(bumpFee(old.GasPrice(), percent), suggested)
+		logger.Debug().Msgf("gasPrice: %v -> %v (suggested: %v)", old.GasPrice(), gasPrice, suggested)
+	case types.DynamicFeeTxType:
+		suggestedTip, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("query gasTipCap error: %w", err)
+		}
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get latest block header error: %w", err)
+		}
+
+		gasTipCap = bigMax(bumpFee(old.GasTipCap(), percent), suggestedTip)
+		gasFeeCap = bumpFee(old.GasFeeCap(), percent)
+		if header.BaseFee != nil {
+			gasFeeCap = bigMax(gasFeeCap, new(big.Int).Add(gasTipCap, new(big.Int).Mul(header.BaseFee, big.NewInt(2))))
+		}
+		gasFeeCap = bigMax(gasFeeCap, gasTipCap)
+		logger.Debug().Msgf("gasTipCap: %v -> %v gasFeeCap: %v -> %v", old.GasTipCap(), gasTipCap, old.GasFeeCap(), gasFeeCap)
+	default:
+		return nil, fmt.Errorf("unsupported tx type: %v", TxTypeName(old.Type()))
+	}
+
+	if ledger {
+		if gasFeeCap != nil {
+			gasPrice = gasFeeCap
+		}
+		logger.Info().Msgf("Transaction type: legacy")
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    old.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), nil
+	}
+
+	switch old.Type() {
+	case types.LegacyTxType:
+		logger.Info().Msgf("Transaction type: legacy")
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    old.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), nil
+	case types.AccessListTxType:
+		logger.Info().Msgf("Transaction type: accessList")
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    old.ChainId(),
+			Nonce:      old.Nonce(),
+			GasPrice:   gasPrice,
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+	default:
+		logger.Info().Msgf("Transaction type: dynamicFee (eip1559)")
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    old.ChainId(),
+			Nonce:      old.Nonce(),
+			GasTipCap:  gasTipCap,
+			GasFeeCap:  gasFeeCap,
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+	}
+}
+
+// CheckMaxFee 替换交易不能降低fee，所以超过network的maxFee时返回错误而不是降低到maxFee
+func CheckMaxFee(net *database.Network, tx *types.Transaction) error {
+	if net.Defaults.MaxFee == "" {
+		return nil
+	}
+	maxFee, err := utils.ParseUnits(net.Defaults.MaxFee, utils.UnitGwei)
+	if err != nil {
+		return fmt.Errorf("parse network: %v maxFee: %v error: %w", net.Name, net.Defaults.MaxFee, err)
+	}
+	if tx.GasFeeCap().Cmp(maxFee) > 0 {
+		return fmt.Errorf("replacement fee: %v exceeds network: %v maxFee: %v gwei", tx.GasFeeCap(), net.Name, net.Defaults.MaxFee)
+	}
+	return nil
+}
+
+// WaitReplacement 等待使用同一nonce的交易中的一个上链，返回上链交易的回执
+// nonce已经被使用但是hashes都没有回执时返回ErrReplacedByUnknown
+func WaitReplacement(ctx context.Context, client *ethclient.Client, from common.Address, nonce uint64, hashes []common.Hash) (*types.Receipt, error) {
+	logger := utils.GetLogger("WaitReplacement")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for head := range WatchHeads(ctx, client, headPollInterval) {
+		// 先查询nonce再查询回执，nonce已经被使用时回执也一定可以查到
+		latest, err := client.NonceAt(ctx, from, head)
+		if err != nil {
+			logger.Debug().Msgf("query nonce at block: %v error: %v", head, err)
+			continue
+		}
+
+		for _, hash := range hashes {
+			receipt, err := client.TransactionReceipt(ctx, hash)
+			if err == nil {
+				return receipt, nil
+			}
+		}
+
+		if latest > nonce {
+			return nil, fmt.Errorf("%w: nonce: %v", ErrReplacedByUnknown, nonce)
+		}
+		logger.Debug().Msgf("nonce: %v not yet used at block: %v", nonce, head)
+	}
+	return nil, ctx.Err()
+}
+
+// bumpFee 按percent提高fee，向上取整，至少提高1 wei
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped = new(big.Int).Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestPriceBump'
+func TestPriceBump(t *testing.T) {
+	percent, err := ParsePriceBump("15%")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), percent)
+
+	percent, err = ParsePriceBump("20")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), percent)
+
+	_, err = ParsePriceBump("5%")
+	assert.Error(t, err)
+	_, err = ParsePriceBump("abc")
+	assert.Error(t, err)
+
+	// 向上取整
+	assert.Equal(t, big.NewInt(115), bumpFee(big.NewInt(100), 15))
+	assert.Equal(t, big.NewInt(12), bumpFee(big.NewInt(10), 15))
+	// 至少提高1 wei
+	assert.Equal(t, big.NewInt(1), bumpFee(big.NewInt(0), 15))
+	assert.Equal(t, big.NewInt(2), bumpFee(big.NewInt(1), 15))
+}
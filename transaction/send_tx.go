@@ -9,10 +9,9 @@ import (
 	"math/big"
 	"met/database"
 	utils "met/utils"
-	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -22,54 +21,168 @@ var (
 )
 
 // 多返回一个types.Transaction是为了当不需要receipt(confirmations=0)时，能知道tx hash
-func SendTx(client *ethclient.Client, from string, tx *types.Transaction, ledger bool, ledgerWallet accounts.Wallet, ledgerAccount *accounts.Account, privateKey *ecdsa.PrivateKey, net *database.Network, noconfirm bool, confirmations int8) (*types.Receipt, *types.Transaction, error) {
+// accountName 用于查询签名策略，overridePolicy为true时可以在输入账户密码后忽略策略
+// confirmations为nil时使用network的默认值
+// nonceAllocated为true表示tx的nonce由BuildTx分配，没有广播时释放，用户指定的nonce不释放
+func SendTx(client *ethclient.Client, from string, accountName string, accountIndex uint, tx *types.Transaction, nonceAllocated bool, ledger bool, ledgerWallet accounts.Wallet, ledgerAccount *accounts.Account, privateKey *ecdsa.PrivateKey, net *database.Network, noconfirm bool, confirmations *int8, overridePolicy bool) (*types.Receipt, *types.Transaction, error) {
 	var err error
 	logger := utils.GetLogger("SendTx")
 
-	signer := types.LatestSignerForChainID(tx.ChainId())
+	// 没有广播时释放BuildTx分配的nonce
+	nonce, sent := tx.Nonce(), false
+	defer func() {
+		var txHash string
+		if sent {
+			txHash = tx.Hash().Hex()
+		}
+		recordNonce(net.Name, from, nonce, txHash, sent, nonceAllocated)
+	}()
+
+	// Check chain id
+	chainID, err := VerifyChainId(context.Background(), client, net)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 未签名的legacy交易没有chain id，签名时使用network的chain id
+	if tx.Type() != types.LegacyTxType && tx.ChainId().Cmp(chainID) != 0 {
+		return nil, nil, fmt.Errorf("%w: tx: %v network: %v", ErrChainIdMismatch, tx.ChainId(), chainID)
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
 	txHash := signer.Hash(tx)
 	logger.Debug().Msgf("tx hash to be signed: %s", txHash)
 
+	// Check policy
+	err = CheckPolicy(accountName, net.Name, tx, overridePolicy)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Sign tx
-	logger.Debug().Msgf("sign transaction")
-	if ledger {
-		chainID, err := client.ChainID(context.Background())
-		if err != nil {
-			logger.Error().Msgf("get chain id error: %v", err)
-			return nil, nil, err
+	sign := func(tx *types.Transaction) (*types.Transaction, error) {
+		logger.Debug().Msgf("sign transaction")
+		if ledger {
+			fmt.Printf("confirm on your ledger device..\n")
+			tx, err := ledgerWallet.SignTx(*ledgerAccount, tx, chainID)
+			if err != nil {
+				logger.Error().Msgf("sign tx error: %v", err)
+			}
+			return tx, err
 		}
-		fmt.Printf("confirm on your ledger device..\n")
-		tx, err = ledgerWallet.SignTx(*ledgerAccount, tx, chainID)
+		return types.SignTx(tx, signer, privateKey)
+	}
+	// 显示交易信息并确认，note不为空时显示在交易信息之后
+	confirm := func(tx *types.Transaction, note string) error {
+		txInfo, err := txSummary(from, ledger, chainID, net, tx)
 		if err != nil {
-			logger.Error().Msgf("sign tx error: %v", err)
-			return nil, nil, err
+			return err
 		}
+		logger.Info().Msg(txInfo + note)
 
-	} else {
-		tx, err = types.SignTx(tx, signer, privateKey)
+		if !noconfirm {
+			input, err := utils.ReadChar("Send ? [y/N] ")
+			if err != nil {
+				RecordTxSignature(accountName, accountIndex, net.Name, tx, "", err)
+				return err
+			}
+
+			if input != 'y' {
+				RecordTxSignature(accountName, accountIndex, net.Name, tx, OutcomeCancelled, nil)
+				return ErrCancel
+			}
+		}
+		return nil
+	}
+	// Send Tx
+	send := func(tx *types.Transaction) error {
+		ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+		defer cancel()
+
+		err := client.SendTransaction(ctx, tx)
+		if IsTxTypeRejected(err) {
+			RecordTxSignature(accountName, accountIndex, net.Name, tx, fmt.Sprintf("%s: %v", OutcomeRejectedType, err), nil)
+		} else {
+			RecordTxSignature(accountName, accountIndex, net.Name, tx, OutcomeSent, err)
+		}
+		return err
+	}
+
+	tx, err = sign(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Debug().Msgf("tx hash: %v", tx.Hash())
+
+	if err = confirm(tx, ""); err != nil {
+		return nil, nil, err
+	}
+
+	err = send(tx)
+	// 节点不支持type 1或者type 2时使用type 0重试
+	if err != nil && IsTxTypeRejected(err) {
+		legacy, fallbackErr := LegacyFallback(tx)
+		if fallbackErr != nil {
+			return nil, nil, fmt.Errorf("%w (fallback to tx type 0: %v)", err, fallbackErr)
+		}
+		// 重新签名后再次确认，说明第一次发送没有成功的原因
+		note := fmt.Sprintf("Note: tx type %v was rejected by the node (%v), the tx is re-signed as tx type %v\n",
+			TxTypeName(tx.Type()), err, TxTypeName(legacy.Type()))
+
+		tx, err = sign(legacy)
 		if err != nil {
 			return nil, nil, err
 		}
+		if err = confirm(tx, note); err != nil {
+			return nil, nil, err
+		}
+		err = send(tx)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	sent = true
+	RecordSpend(accountName, net.Name, tx)
+	RecordTransaction(accountName, accountIndex, net.Name, tx)
+
+	if confirmations == nil {
+		confirmations = new(int8)
+		if net.Defaults.Confirmations != nil {
+			*confirmations = *net.Defaults.Confirmations
+		}
 	}
 
-	logger.Debug().Msgf("tx hash: %v", tx.Hash())
+	ctx2, cancel2 := context.WithTimeout(context.Background(), net.ReceiptTimeout())
+	defer cancel2()
+
+	receipt, err := WaitTx(ctx2, client, net, tx.Hash(), *confirmations)
+	if err != nil {
+		logger.Error().Err(err).Msgf("wait tx")
+	}
+	if receipt != nil {
+		RecordReceipt(receipt)
+	}
+
+	return receipt, tx, nil
+}
 
+// txSummary 发送之前显示的交易信息
+func txSummary(from string, ledger bool, chainID *big.Int, net *database.Network, tx *types.Transaction) (string, error) {
 	gasPrice, err := utils.Wei2Gwei(tx.GasPrice().String())
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
 	tipCap, err := utils.Wei2Gwei(tx.GasTipCap().String())
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
 	feeCap, err := utils.Wei2Gwei(tx.GasFeeCap().String())
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
 
 	value, err := utils.FormatUnits(tx.Value().String(), utils.UnitEth)
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
 
 	to := "EMPTY (contract creation)"
@@ -82,66 +195,51 @@ func SendTx(client *ethclient.Client, from string, tx *types.Transaction, ledger
 		accountType = "ledger"
 	}
 
-	txInfo := fmt.Sprintf(`
+	info := fmt.Sprintf(`
 Transaction to be sent
 From:                %s (%s)
 To:                  %s
 Value:               %s (%s %s)
 Data:                0x%s
+Type:                %s
 Nonce:               %v
 ChainId:             %s
 GasLimit:            %v
 GasPrice:            %s (%s Gwei)
 GasTipCap:           %s (%s Gwei)
 GasFeeCap:           %s (%s Gwei)
+AccessList:          %s
 `,
 		from, accountType,
 		to,
 		tx.Value().String(), value, net.Symbol,
 		hex.EncodeToString(tx.Data()),
+		TxTypeName(tx.Type()),
 		tx.Nonce(),
-		tx.ChainId().String(),
+		chainID.String(),
 		tx.Gas(),
 		tx.GasPrice().String(), gasPrice,
 		tx.GasTipCap().String(), tipCap,
-		tx.GasFeeCap().String(), feeCap)
-
-	logger.Info().Msgf(txInfo)
+		tx.GasFeeCap().String(), feeCap,
+		FormatAccessList(tx.AccessList()))
 
-	if !noconfirm {
-		input, err := utils.ReadChar("Send ? [y/N] ")
+	if tx.Type() == types.BlobTxType {
+		blobFeeCap, err := utils.Wei2Gwei(tx.BlobGasFeeCap().String())
 		if err != nil {
-			return nil, nil, err
+			return "", err
 		}
-
-		if input != 'y' {
-			return nil, nil, ErrCancel
+		info += fmt.Sprintf("BlobFeeCap:          %s (%s Gwei)\nBlobGas:             %v\nBlobHashes:          %v\n",
+			tx.BlobGasFeeCap().String(), blobFeeCap, tx.BlobGas(), len(tx.BlobHashes()))
+		for _, hash := range tx.BlobHashes() {
+			info += fmt.Sprintf("  %s\n", hash.Hex())
 		}
-
 	}
-
-	ctx, cancel := utils.DefaultTimeoutContext()
-	defer cancel()
-
-	// Send Tx
-	err = client.SendTransaction(ctx, tx)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	ctx2, cancel2 := utils.DefaultTimeoutContext()
-	defer cancel2()
-
-	receipt, err := waitTx(ctx2, client, tx, confirmations)
-	if err != nil {
-		logger.Error().Err(err).Msgf("wait tx")
-	}
-
-	return receipt, tx, nil
+	return info, nil
 }
 
-func waitTx(ctx context.Context, client *ethclient.Client, tx *types.Transaction, confirmations int8) (*types.Receipt, error) {
-	logger := utils.GetLogger("waitTx")
+// WaitTx 等待交易上链，confirmations > 0 时再等待对应数量的区块，confirmations < 0 时不等待
+func WaitTx(ctx context.Context, client *ethclient.Client, net *database.Network, txHash common.Hash, confirmations int8) (*types.Receipt, error) {
+	logger := utils.GetLogger("WaitTx")
 
 	if confirmations < 0 {
 		logger.Debug().Msgf("confirmations < 0,do not get query receipt")
@@ -149,7 +247,7 @@ func waitTx(ctx context.Context, client *ethclient.Client, tx *types.Transaction
 	}
 
 	logger.Info().Msgf("query receipt")
-	receipt, err := bind.WaitMined(ctx, client, tx)
+	receipt, err := WaitReceipt(ctx, client, txHash)
 	if err != nil {
 		return nil, err
 	}
@@ -158,33 +256,14 @@ func waitTx(ctx context.Context, client *ethclient.Client, tx *types.Transaction
 	if confirmations > 0 {
 		logger.Info().Msgf("waiting for %v confirmations..", confirmations)
 
-		ticker := time.NewTicker(time.Second)
-		defer ticker.Stop()
-
-		ctx2, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		ctx2, cancel := context.WithTimeout(context.Background(), net.ReceiptTimeout())
 		defer cancel()
 
-	OUTTER:
-		for {
-			// get latest block
-			h, err := client.HeaderByNumber(ctx2, nil)
-			if err != nil {
-				logger.Error().Err(err).Msgf("query latest block header")
-			} else {
-				diff := new(big.Int).Sub(h.Number, receipt.BlockNumber)
-				logger.Debug().Msgf("diff block number: %v (latest: %v mined: %v)", diff.String(), h.Number, receipt.BlockNumber)
-				if diff.Cmp(big.NewInt(int64(confirmations))) >= 0 {
-					logger.Debug().Msgf("confirmations meet")
-					break OUTTER
-				}
-			}
-
-			select {
-			case <-ctx2.Done():
-				logger.Warn().Msgf("context done before confirmations completed")
-				break OUTTER
-			case <-ticker.C:
-			}
+		err = WaitConfirmations(ctx2, client, receipt.BlockNumber, uint64(confirmations))
+		if err != nil {
+			logger.Warn().Msgf("context done before confirmations completed")
+		} else {
+			logger.Debug().Msgf("confirmations meet")
 		}
 	}
 
@@ -0,0 +1,262 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"met/database"
+	"met/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	ErrSimulationReverted = errors.New("simulation reverted")
+
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector  = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons solidity Panic(uint256)的错误码
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler inserted panic",
+	0x01: "assert failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid encoded storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized internal function",
+}
+
+// Simulation 交易在pending区块上执行eth_call的结果
+type Simulation struct {
+	Reverted bool
+	// 执行成功时为返回值，revert时为revert data
+	ReturnData []byte
+	// 解析后的返回值或者revert原因
+	Result string
+}
+
+func (s *Simulation) String() string {
+	if s.Reverted {
+		return fmt.Sprintf(`
+Simulation (eth_call at pending block)
+Result:              reverted
+Reason:              %s
+`, s.Result)
+	}
+	return fmt.Sprintf(`
+Simulation (eth_call at pending block)
+Result:              success
+Return:              %s
+`, s.Result)
+}
+
+// Simulate 在pending区块上执行eth_call，使用abis解析返回值和revert原因
+// 节点返回的执行错误(revert、余额不足等)作为Reverted的结果返回，只有rpc请求失败时返回error
+func Simulate(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg, abis []*abi.ABI) (*Simulation, error) {
+	output, err := client.PendingCallContract(ctx, msg)
+	if err == nil {
+		return &Simulation{ReturnData: output, Result: decodeReturn(abis, msg.Data, output)}, nil
+	}
+
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return nil, fmt.Errorf("eth_call error: %w", err)
+	}
+
+	sim := &Simulation{Reverted: true, Result: err.Error()}
+	if data, ok := revertData(err); ok {
+		sim.ReturnData = data
+		sim.Result = DecodeRevert(abis, data)
+	}
+	return sim, nil
+}
+
+// SimulateBeforeSend 发送之前执行Simulate并打印结果，revert时返回ErrSimulationReverted
+func SimulateBeforeSend(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg, abis []*abi.ABI) (*Simulation, error) {
+	logger := utils.GetLogger("SimulateBeforeSend")
+
+	sim, err := Simulate(ctx, client, msg, abis)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info().Msgf(sim.String())
+
+	if sim.Reverted {
+		return sim, fmt.Errorf("%w: %v", ErrSimulationReverted, sim.Result)
+	}
+	return sim, nil
+}
+
+// SimulateSend 构造交易的eth_call并执行SimulateBeforeSend，to为空时为合约部署
+// abiJson为abi json或者内置abi名称，和内置abi一起用于解析返回值和自定义错误
+// sidecar不为空时带上blob hashes和maxFeePerBlobGas
+func SimulateSend(client *ethclient.Client, net *database.Network, from, to string, value *big.Int, data []byte, abiJson string, sidecar *types.BlobTxSidecar) (*Simulation, error) {
+	abis, err := DecodeAbis(abiJson)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := ethereum.CallMsg{From: common.HexToAddress(from), Value: value, Data: data}
+	if to != "" {
+		toAddress := common.HexToAddress(to)
+		msg.To = &toAddress
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), net.RequestTimeout())
+	defer cancel()
+
+	// 合约可以通过BLOBHASH读取blob的versioned hash
+	if sidecar != nil {
+		msg.BlobHashes = sidecar.BlobHashes()
+		msg.BlobGasFeeCap, err = EstimateBlobFeeCap(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return SimulateBeforeSend(ctx, client, msg, abis)
+}
+
+// DecodeRevert 解析revert data，依次尝试Error(string)、Panic(uint256)和abis中的自定义错误
+// eg: Error("insufficient balance") Panic(0x11: arithmetic underflow or overflow) InsufficientBalance(available=1, required=2)
+func DecodeRevert(abis []*abi.ABI, data []byte) string {
+	if len(data) == 0 {
+		return "execution reverted (no reason)"
+	}
+	if len(data) < 4 {
+		return fmt.Sprintf("execution reverted (data: %v)", hexutil.Encode(data))
+	}
+
+	switch {
+	case bytes.Equal(data[:4], revertSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err == nil {
+			return fmt.Sprintf("Error(%q)", reason)
+		}
+	case bytes.Equal(data[:4], panicSelector):
+		values, err := abi.Arguments{{Type: mustType("uint256")}}.Unpack(data[4:])
+		if err == nil {
+			code := values[0].(*big.Int)
+			reason := "unknown panic code"
+			if code.IsUint64() {
+				if r, ok := panicReasons[code.Uint64()]; ok {
+					reason = r
+				}
+			}
+			return fmt.Sprintf("Panic(%#x: %s)", code, reason)
+		}
+	default:
+		for _, abiObj := range abis {
+			for _, abiErr := range abiObj.Errors {
+				if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+					continue
+				}
+				values, err := abiErr.Inputs.Unpack(data[4:])
+				if err != nil {
+					continue
+				}
+				return fmt.Sprintf("%s(%s)", abiErr.Name, formatArgs(abiErr.Inputs, values))
+			}
+		}
+	}
+	return fmt.Sprintf("unknown error %v (data: %v)", hexutil.Encode(data[:4]), hexutil.Encode(data))
+}
+
+// RevertError 解析后的revert原因，Unwrap返回节点的错误
+type RevertError struct {
+	Reason string
+	Data   []byte
+	err    error
+}
+
+func (e *RevertError) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.err
+}
+
+// DecodeRevertError 节点返回的错误包含revert data时转换为RevertError，用于估算gas失败时
+func DecodeRevertError(err error, abis []*abi.ABI) error {
+	data, ok := revertData(err)
+	if !ok {
+		return err
+	}
+	return &RevertError{Reason: DecodeRevert(abis, data), Data: data, err: err}
+}
+
+// revertData 节点在错误的data字段中返回revert data
+func revertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	s, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeReturn 按input的方法解析返回值，无法解析时显示hex
+func decodeReturn(abis []*abi.ABI, input []byte, output []byte) string {
+	if len(input) >= 4 {
+		for _, abiObj := range abis {
+			method, err := abiObj.MethodById(input[:4])
+			if err != nil || len(method.Outputs) == 0 {
+				continue
+			}
+			values, err := method.Outputs.Unpack(output)
+			if err != nil {
+				continue
+			}
+			return fmt.Sprintf("(%s)", formatArgs(method.Outputs, values))
+		}
+	}
+	if len(output) == 0 {
+		return "(empty)"
+	}
+	return hexutil.Encode(output)
+}
+
+func formatArgs(args abi.Arguments, values []any) string {
+	var argStrs []string
+	for i, arg := range args {
+		if i >= len(values) {
+			break
+		}
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		argStrs = append(argStrs, fmt.Sprintf("%s=%s", name, formatArg(values[i])))
+	}
+	return strings.Join(argStrs, ", ")
+}
+
+func mustType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
@@ -0,0 +1,28 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestDecodeRevert'
+func TestDecodeRevert(t *testing.T) {
+	custom, err := ParseAbiJson(`[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`)
+	assert.NoError(t, err)
+	abis := []*abi.ABI{custom}
+
+	assert.Equal(t, `Error("boom")`, DecodeRevert(abis, common.FromHex("0x08c379a000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000004626f6f6d00000000000000000000000000000000000000000000000000000000")))
+	assert.Equal(t, "Panic(0x11: arithmetic underflow or overflow)", DecodeRevert(abis, common.FromHex("0x4e487b710000000000000000000000000000000000000000000000000000000000000011")))
+	assert.Equal(t, "InsufficientBalance(available=1, required=2)", DecodeRevert(abis, common.FromHex("0xcf47918100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002")))
+	assert.Equal(t, "unknown error 0xcf479181 (data: 0xcf47918100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002)", DecodeRevert(nil, common.FromHex("0xcf47918100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002")))
+	assert.Equal(t, "execution reverted (no reason)", DecodeRevert(abis, nil))
+
+	// erc20 balanceOf的返回值
+	input := common.FromHex("0x70a082310000000000000000000000002c7536e3605d9c16a7a3d7b1898e529396a65c23")
+	output := common.FromHex("0x0000000000000000000000000000000000000000000000000000000000000064")
+	assert.Equal(t, "(arg0=100)", decodeReturn(builtinAbis(), input, output))
+	assert.Equal(t, "(empty)", decodeReturn(builtinAbis(), []byte{1, 2, 3, 4}, nil))
+}
@@ -0,0 +1,76 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	mTypes "met/types"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// txTypeRejections 节点不支持交易类型时的错误信息，不同客户端的错误信息不同
+var txTypeRejections = []string{
+	"transaction type not supported",
+	"tx type not supported",
+	"unsupported transaction type",
+	"unsupported tx type",
+	"invalid transaction type",
+	"typed transaction too short",
+	"rlp: expected input list for types.legacytx",
+}
+
+// checkTxType 使用最新区块头检查链是否支持交易类型，type 1无法从区块头判断，发送被拒绝时SendTx会使用type 0重试
+func checkTxType(ctx context.Context, client *ethclient.Client, txType mTypes.TxType) error {
+	if txType != mTypes.TxTypeDynamicFee && txType != mTypes.TxTypeBlob {
+		return nil
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("get latest block header error: %w", err)
+	}
+	if header.BaseFee == nil {
+		return fmt.Errorf("chain does not support tx type %v: no baseFee in latest block (eip1559)", txType)
+	}
+	if txType == mTypes.TxTypeBlob && header.ExcessBlobGas == nil {
+		return fmt.Errorf("chain does not support tx type %v: no excessBlobGas in latest block (eip4844)", txType)
+	}
+	return nil
+}
+
+// IsTxTypeRejected 广播错误是否为节点不支持交易类型
+func IsTxTypeRejected(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, rejection := range txTypeRejections {
+		if strings.Contains(msg, rejection) {
+			return true
+		}
+	}
+	return false
+}
+
+// LegacyFallback 把未签名的type 1或者type 2交易转换为type 0，type 2使用gasFeeCap作为gasPrice
+// access list会影响gasLimit，有access list时不转换
+func LegacyFallback(tx *types.Transaction) (*types.Transaction, error) {
+	if tx.Type() != types.AccessListTxType && tx.Type() != types.DynamicFeeTxType {
+		return nil, fmt.Errorf("tx type %v can not fall back to tx type 0", TxTypeName(tx.Type()))
+	}
+	if len(tx.AccessList()) > 0 {
+		return nil, errors.New("tx has access list, use --txType 0 without --access-list")
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: tx.GasFeeCap(),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}), nil
+}
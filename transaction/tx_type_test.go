@@ -0,0 +1,120 @@
+package transaction
+
+import (
+	"errors"
+	"math/big"
+	"met/database"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -count=1 -v met/transaction -run 'TestLegacyFallback'
+func TestLegacyFallback(t *testing.T) {
+	assert.True(t, IsTxTypeRejected(errors.New("transaction type not supported")))
+	assert.True(t, IsTxTypeRejected(errors.New("Unsupported transaction type: 2")))
+	assert.False(t, IsTxTypeRejected(errors.New("nonce too low")))
+	assert.False(t, IsTxTypeRejected(nil))
+
+	to := common.HexToAddress("0x41BB7A889F20b71E6AaBa492298041E84691C41B")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1337),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(30),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(100),
+	})
+	legacy, err := LegacyFallback(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.LegacyTxType), legacy.Type())
+	assert.Equal(t, big.NewInt(30), legacy.GasPrice())
+	assert.Equal(t, uint64(7), legacy.Nonce())
+	assert.Equal(t, &to, legacy.To())
+
+	// 有access list时gasLimit可能不够
+	tx = types.NewTx(&types.AccessListTx{
+		ChainID:    big.NewInt(1337),
+		GasPrice:   big.NewInt(30),
+		Gas:        21000,
+		To:         &to,
+		AccessList: types.AccessList{{Address: to}},
+	})
+	_, err = LegacyFallback(tx)
+	assert.Error(t, err)
+
+	_, err = LegacyFallback(legacy)
+	assert.Error(t, err)
+}
+
+// fakeLegacyEth 节点只接受type 0交易
+type fakeLegacyEth struct {
+	sent []*types.Transaction
+}
+
+func (*fakeLegacyEth) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1337))
+}
+
+func (f *fakeLegacyEth) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if tx.Type() != types.LegacyTxType {
+		return common.Hash{}, errors.New("transaction type not supported")
+	}
+	f.sent = append(f.sent, tx)
+	return tx.Hash(), nil
+}
+
+// go test -count=1 -v met/transaction -run 'TestSendTxLegacyFallback'
+func TestSendTxLegacyFallback(t *testing.T) {
+	database.InitDB("silent", filepath.Join(t.TempDir(), "met.db"))
+
+	eth := &fakeLegacyEth{}
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", eth))
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	defer server.Stop()
+	defer client.Close()
+
+	privateKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	to := common.HexToAddress("0x41BB7A889F20b71E6AaBa492298041E84691C41B")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1337),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(30),
+		Gas:       21000,
+		To:        &to,
+	})
+
+	confirmations := int8(-1)
+	net := &database.Network{Name: "dev", ChainId: 1337}
+	_, sentTx, err := SendTx(client, from, "fallback", 0, tx, false, false, nil, nil, privateKey, net, true, &confirmations, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.LegacyTxType), sentTx.Type())
+	assert.Len(t, eth.sent, 1)
+
+	// 被拒绝的签名和重新签名的交易都要记录
+	logs, err := database.QuerySigningLogs("fallback", "", time.Time{}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 2)
+	var outcomes []string
+	for _, log := range logs {
+		outcomes = append(outcomes, log.Outcome)
+	}
+	assert.Contains(t, outcomes, OutcomeSent)
+	assert.Contains(t, outcomes, OutcomeRejectedType+": transaction type not supported")
+}
@@ -42,6 +42,12 @@ func (f AccountDetails) AsString(insecure bool) string {
 	var msgArray []string
 	msgArray = append(msgArray, fmt.Sprintf("\nAccount Name: %s\n", f.Name))
 	msgArray = append(msgArray, fmt.Sprintf("Account Type: %s\n", f.Type))
+	if f.Tags != "" {
+		msgArray = append(msgArray, fmt.Sprintf("Tags: %s\n", f.Tags))
+	}
+	if f.Groups != "" {
+		msgArray = append(msgArray, fmt.Sprintf("Groups: %s\n", f.Groups))
+	}
 
 	if f.Encrypted {
 		msgArray = append(msgArray, "Account Status: locked\n")
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	database "met/database"
+	hd "met/hd"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// 每个goroutine派生的index数量
+	findChunkSize = 500
+)
+
+// FoundAccount 控制某个地址的账户
+type FoundAccount struct {
+	Name  string
+	Type  string
+	Index uint
+	Path  string
+}
+
+// FindAccount 在所有未加锁的账户中查找控制address的账户
+// private key账户直接比较，mnemonic账户并行派生[0, depth)的index
+func FindAccount(address string, depth uint) ([]FoundAccount, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid address: %v", address)
+	}
+	target := common.HexToAddress(address)
+
+	accounts, err := database.QueryAllAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		found    []FoundAccount
+		firstErr error
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, runtime.NumCPU())
+	)
+
+	for i := range accounts {
+		account := accounts[i]
+		if account.Encrypted {
+			continue
+		}
+
+		switch account.Type {
+		case PrivateKeyType:
+			details, err := AccountToDetails(&account)
+			if err != nil {
+				return nil, fmt.Errorf("account: %v error: %w", account.Name, err)
+			}
+			if common.HexToAddress(details.address) == target {
+				found = append(found, FoundAccount{Name: account.Name, Type: account.Type})
+			}
+
+		case MnemonicType:
+			for start := uint(0); start < depth; start += findChunkSize {
+				count := min(findChunkSize, depth-start)
+
+				wg.Add(1)
+				sem <- struct{}{}
+				go func(start, count uint) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+
+					addresses, paths, err := hd.DeriveAddresses(account.Value, account.Passphrase, account.PathFormat, start, count)
+
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						if firstErr == nil {
+							firstErr = fmt.Errorf("derive account: %v error: %w", account.Name, err)
+						}
+						return
+					}
+					for j := range addresses {
+						if common.HexToAddress(addresses[j]) == target {
+							found = append(found, FoundAccount{Name: account.Name, Type: account.Type, Index: start + uint(j), Path: paths[j]})
+						}
+					}
+				}(start, count)
+
+				// path format中没有x时只有一个地址
+				if !strings.Contains(account.PathFormat, "x") {
+					break
+				}
+			}
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Name != found[j].Name {
+			return found[i].Name < found[j].Name
+		}
+		return found[i].Index < found[j].Index
+	})
+
+	return found, nil
+}
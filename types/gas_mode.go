@@ -1,5 +1,7 @@
 package types
 
+import "fmt"
+
 type GasMode int8
 
 const (
@@ -21,3 +23,18 @@ var (
 		"eip1559": 3,
 	}
 )
+
+// ParseGasMode 1559是eip1559的简写，空字符串返回0，表示未指定
+func ParseGasMode(s string) (GasMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if s == "1559" {
+		return GasModeEip1559, nil
+	}
+	value, ok := GasMode_value[s]
+	if !ok {
+		return 0, fmt.Errorf("invalid gas mode: %v", s)
+	}
+	return GasMode(value), nil
+}
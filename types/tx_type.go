@@ -0,0 +1,31 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TxType 交易类型(eip2718)，和gasMode无关，TxTypeUnspecified表示由gasMode决定
+type TxType int8
+
+const (
+	TxTypeUnspecified TxType = -1
+
+	TxTypeLegacy     TxType = 0
+	TxTypeAccessList TxType = 1
+	TxTypeDynamicFee TxType = 2
+	TxTypeBlob       TxType = 3
+	TxTypeSetCode    TxType = 4
+)
+
+// ParseTxType 0-4，空字符串返回TxTypeUnspecified
+func ParseTxType(s string) (TxType, error) {
+	if s == "" {
+		return TxTypeUnspecified, nil
+	}
+	value, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || value > uint64(TxTypeSetCode) {
+		return TxTypeUnspecified, fmt.Errorf("invalid tx type: %v, available: 0 1 2 3 4", s)
+	}
+	return TxType(value), nil
+}
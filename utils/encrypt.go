@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"strings"
 
@@ -45,3 +46,27 @@ func Decrypt(passphrase, ciphertext string) string {
 	data, _ = aesgcm.Open(nil, iv, data, nil)
 	return string(data)
 }
+
+// HashPassword 生成密码的摘要(salt-key)，用于之后校验密码，不保存明文
+func HashPassword(password string) string {
+	key, salt := deriveKey(password, nil)
+	return hex.EncodeToString(salt) + "-" + hex.EncodeToString(key)
+}
+
+// VerifyPassword 校验密码是否与HashPassword生成的摘要匹配
+func VerifyPassword(password, hashed string) bool {
+	arr := strings.Split(hashed, "-")
+	if len(arr) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(arr[0])
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(arr[1])
+	if err != nil {
+		return false
+	}
+	key, _ := deriveKey(password, salt)
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
@@ -14,3 +14,13 @@ func TestEncrypt(t *testing.T) {
 	decrypted := Decrypt(passphrase+"cc", encrypted)
 	t.Logf("decrypted: '%v'", decrypted)
 }
+
+func TestVerifyPassword(t *testing.T) {
+	hashed := HashPassword("1234")
+	if !VerifyPassword("1234", hashed) {
+		t.Fatalf("verify right password failed")
+	}
+	if VerifyPassword("12345", hashed) {
+		t.Fatalf("verify wrong password succeeded")
+	}
+}
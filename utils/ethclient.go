@@ -1,19 +1,350 @@
 package utils
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
 )
 
-func DialRpc(ctx context.Context, rpc string) (*ethclient.Client, error) {
-	loggers := GetLogger("DialRpc")
+const (
+	// 落后最高区块超过MaxRpcLag的rpc会被剔除
+	MaxRpcLag = 5
+	// 每个rpc遇到传输错误时的重试次数
+	RpcRetries = 2
 
-	loggers.Info().Msgf("Dial rpc: %v", rpc)
-	client, err := ethclient.DialContext(ctx, rpc)
+	rpcHealthTimeout = 5 * time.Second
+)
+
+var (
+	ErrNoHealthyRpc = errors.New("no healthy rpc")
+
+	// 写操作和nonce查询固定发到同一个rpc，保证nonce一致
+	pinnedMethods = map[string]bool{
+		"eth_sendRawTransaction":  true,
+		"eth_sendTransaction":     true,
+		"eth_getTransactionCount": true,
+	}
+)
+
+type RpcEndpoint struct {
+	Url string
+	// 权重越大越容易被选为主rpc，0表示只作为备用
+	Weight uint
+}
+
+// DialRpcs 连接多个rpc，返回的client在传输错误时重试，并切换到下一个健康的rpc
+// 多个rpc时，先查询每个rpc的区块高度，剔除失败和落后的rpc，再按权重随机选择主rpc
+// 写操作固定发到主rpc
+// ws和ipc支持newHeads等订阅，被选为主rpc时直接连接，不支持切换
+// options为network的header、认证、TLS和代理设置
+func DialRpcs(ctx context.Context, endpoints []RpcEndpoint, options TransportOptions) (*ethclient.Client, error) {
+	logger := GetLogger("DialRpcs")
+
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no rpc")
+	}
+
+	// 解析环境变量和文件引用，解析失败的rpc不使用
+	var expanded []RpcEndpoint
+	for _, endpoint := range endpoints {
+		rawUrl, err := ExpandSecret(endpoint.Url)
+		if err != nil {
+			if len(endpoints) == 1 {
+				return nil, err
+			}
+			logger.Warn().Msgf("skip rpc: %v", err)
+			continue
+		}
+		expanded = append(expanded, RpcEndpoint{Url: rawUrl, Weight: endpoint.Weight})
+	}
+	if len(expanded) == 0 {
+		return nil, ErrNoHealthyRpc
+	}
+	endpoints = expanded
+
+	healthy := endpoints
+	if len(endpoints) > 1 {
+		healthy = HealthyRpcs(ctx, endpoints, MaxRpcLag, options)
+		if len(healthy) == 0 {
+			return nil, ErrNoHealthyRpc
+		}
+	}
+
+	ordered := orderByWeight(healthy)
+	logger.Info().Msgf("Dial rpc: %v", RedactUrl(ordered[0].Url))
+
+	// ws和ipc是长连接，只连接主rpc；主rpc是http时，只在http rpc之间切换
+	if isHttpUrl(ordered[0].Url) {
+		var httpEndpoints []RpcEndpoint
+		for _, endpoint := range ordered {
+			if isHttpUrl(endpoint.Url) {
+				httpEndpoints = append(httpEndpoints, endpoint)
+			}
+		}
+		ordered = httpEndpoints
+	}
+
+	rpcClient, err := dialRpcClient(ctx, ordered, RpcRetries, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// HealthyRpcs 并行查询区块高度，返回查询成功且落后最高区块不超过maxLag的rpc，顺序不变
+func HealthyRpcs(ctx context.Context, endpoints []RpcEndpoint, maxLag uint64, options TransportOptions) []RpcEndpoint {
+	logger := GetLogger("HealthyRpcs")
+
+	heads := make([]uint64, len(endpoints))
+	errs := make([]error, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			heads[i], errs[i] = RpcBlockNumber(ctx, endpoints[i].Url, options)
+		}(i)
+	}
+	wg.Wait()
+
+	var highest uint64
+	for i := range endpoints {
+		if errs[i] == nil && heads[i] > highest {
+			highest = heads[i]
+		}
+	}
+
+	var healthy []RpcEndpoint
+	for i, endpoint := range endpoints {
+		if errs[i] != nil {
+			logger.Warn().Msgf("eject rpc: %v error: %v", RedactUrl(endpoint.Url), errs[i])
+			continue
+		}
+		if heads[i]+maxLag < highest {
+			logger.Warn().Msgf("eject rpc: %v head: %v lag behind: %v", RedactUrl(endpoint.Url), heads[i], highest)
+			continue
+		}
+		healthy = append(healthy, endpoint)
+	}
+
+	return healthy
+}
+
+// dialRpcClient http(s)使用failoverTransport，dial时使用隐藏了密钥的url，避免错误信息中包含密钥
+// ws(s)和ipc只连接endpoints[0]
+func dialRpcClient(ctx context.Context, endpoints []RpcEndpoint, retries int, options TransportOptions) (*rpc.Client, error) {
+	headers, err := options.HttpHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := options.HttpTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	if isWsUrl(endpoints[0].Url) {
+		// websocket握手使用相同的TLS和代理设置
+		dialer := websocket.Dialer{
+			Proxy:            base.Proxy,
+			TLSClientConfig:  base.TLSClientConfig,
+			HandshakeTimeout: 45 * time.Second,
+			ReadBufferSize:   1024,
+			WriteBufferSize:  1024,
+		}
+		return rpc.DialOptions(ctx, endpoints[0].Url, rpc.WithHeaders(headers), rpc.WithWebsocketDialer(dialer))
+	}
+	if !isHttpUrl(endpoints[0].Url) {
+		// ipc
+		return rpc.DialOptions(ctx, endpoints[0].Url)
+	}
+	transport := &failoverTransport{
+		endpoints: endpoints,
+		retries:   retries,
+		headers:   headers,
+		base:      base,
+	}
+	return rpc.DialOptions(ctx, RedactUrl(endpoints[0].Url), rpc.WithHTTPClient(&http.Client{Transport: transport}))
+}
+
+// RpcBlockNumber 不重试，直接查询一个rpc(已解析引用)的区块高度
+func RpcBlockNumber(ctx context.Context, rawUrl string, options TransportOptions) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, rpcHealthTimeout)
+	defer cancel()
+
+	client, err := dialRpcClient(ctx, []RpcEndpoint{{Url: rawUrl}}, 0, options)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	var head hexutil.Uint64
+	err = client.CallContext(ctx, &head, "eth_blockNumber")
+	return uint64(head), err
+}
+
+// orderByWeight 按权重随机选出主rpc放在第一个，其余保持原来的顺序
+func orderByWeight(endpoints []RpcEndpoint) []RpcEndpoint {
+	var total uint
+	for _, endpoint := range endpoints {
+		total += endpoint.Weight
+	}
+
+	primary := 0
+	if total > 0 {
+		n := uint(rand.Int63n(int64(total)))
+		for i, endpoint := range endpoints {
+			if n < endpoint.Weight {
+				primary = i
+				break
+			}
+			n -= endpoint.Weight
+		}
+	}
+
+	ordered := []RpcEndpoint{endpoints[primary]}
+	ordered = append(ordered, endpoints[:primary]...)
+	ordered = append(ordered, endpoints[primary+1:]...)
+	return ordered
+}
+
+func isHttpUrl(rawUrl string) bool {
+	return strings.HasPrefix(rawUrl, "http://") || strings.HasPrefix(rawUrl, "https://")
+}
+
+func isWsUrl(rawUrl string) bool {
+	return strings.HasPrefix(rawUrl, "ws://") || strings.HasPrefix(rawUrl, "wss://")
+}
+
+// failoverTransport 把json rpc请求发给当前rpc，传输错误时重试，然后切换到下一个rpc
+// endpoints[0]是主rpc，写操作只发给主rpc
+type failoverTransport struct {
+	endpoints []RpcEndpoint
+	// 每个rpc的重试次数
+	retries int
+	// 已解析引用的header
+	headers http.Header
+	base    http.RoundTripper
+
+	mu      sync.Mutex
+	current int
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := GetLogger("failoverTransport")
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	start := t.current
+	t.mu.Unlock()
+
+	// 写操作只发给主rpc，读操作从当前rpc开始依次尝试
+	pinned := isPinnedRequest(body)
+	candidates := []int{0}
+	if !pinned {
+		candidates = candidates[:0]
+		for i := 0; i < len(t.endpoints); i++ {
+			candidates = append(candidates, (start+i)%len(t.endpoints))
+		}
+	}
+
+	var lastErr error
+	for _, index := range candidates {
+		endpoint := t.endpoints[index]
+		for attempt := 0; attempt <= t.retries; attempt++ {
+			if err := req.Context().Err(); err != nil {
+				return nil, err
+			}
+
+			resp, err := t.send(req, endpoint.Url, body)
+			if err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+				if !pinned && index != start {
+					t.mu.Lock()
+					t.current = index
+					t.mu.Unlock()
+				}
+				return resp, nil
+			}
+			if err == nil {
+				resp.Body.Close()
+				err = fmt.Errorf("http status: %v", resp.Status)
+			}
+			lastErr = err
+			logger.Warn().Msgf("rpc: %v attempt: %d error: %v", RedactUrl(endpoint.Url), attempt+1, err)
+		}
+		if len(candidates) > 1 {
+			logger.Warn().Msgf("rpc: %v failed, fail over to next rpc", RedactUrl(endpoint.Url))
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (t *failoverTransport) send(req *http.Request, rawUrl string, body []byte) (*http.Response, error) {
+	target, err := url.Parse(rawUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	return client, nil
+	clone := req.Clone(req.Context())
+	clone.URL = target
+	clone.Host = target.Host
+	for k, v := range t.headers {
+		clone.Header[k] = v
+	}
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return t.base.RoundTrip(clone)
+}
+
+// isPinnedRequest body是单个请求或者batch请求
+func isPinnedRequest(body []byte) bool {
+	type message struct {
+		Method string `json:"method"`
+	}
+
+	var messages []message
+	if err := json.Unmarshal(body, &messages); err != nil {
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return false
+		}
+		messages = []message{msg}
+	}
+
+	for _, msg := range messages {
+		if pinnedMethods[msg.Method] {
+			return true
+		}
+	}
+	return false
 }
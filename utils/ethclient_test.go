@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRpcServer(head uint64, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "eth_chainId") {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x%x"}`, head)
+	}))
+}
+
+// go test -count=1 -v met/utils -run 'TestDialRpcsFailover'
+func TestDialRpcsFailover(t *testing.T) {
+	var goodCalls, laggingCalls, downCalls atomic.Int32
+
+	good := newRpcServer(100, &goodCalls)
+	defer good.Close()
+	lagging := newRpcServer(10, &laggingCalls)
+	defer lagging.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downCalls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	endpoints := []RpcEndpoint{{Url: down.URL, Weight: 1}, {Url: lagging.URL, Weight: 1}, {Url: good.URL, Weight: 1}}
+	healthy := HealthyRpcs(context.Background(), endpoints, MaxRpcLag, TransportOptions{})
+	assert.Equal(t, []RpcEndpoint{{Url: good.URL, Weight: 1}}, healthy)
+
+	// 直接使用transport，跳过健康检查，down出错后切换到good
+	transport := &failoverTransport{endpoints: []RpcEndpoint{endpoints[0], endpoints[2]}, retries: RpcRetries, base: http.DefaultTransport}
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(down.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, transport.current)
+
+	// 写操作固定发给主rpc，不切换
+	goodBefore := goodCalls.Load()
+	_, err = client.Post(down.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction"}`))
+	assert.Error(t, err)
+	assert.Equal(t, goodBefore, goodCalls.Load())
+	assert.Equal(t, int32(2*(RpcRetries+1)), downCalls.Load()-1)
+}
+
+// go test -count=1 -v met/utils -run 'TestDialRpcsHeaders'
+func TestDialRpcsHeaders(t *testing.T) {
+	t.Setenv("MET_TEST_TOKEN", "secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Api-Key") != "k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+	}))
+	defer server.Close()
+
+	endpoints := []RpcEndpoint{{Url: server.URL, Weight: 1}}
+
+	client, err := DialRpcs(context.Background(), endpoints, TransportOptions{})
+	assert.NoError(t, err)
+	_, err = client.ChainID(context.Background())
+	assert.Error(t, err)
+
+	options := TransportOptions{BearerToken: "${MET_TEST_TOKEN}", Headers: map[string]string{"X-Api-Key": "k"}}
+	client, err = DialRpcs(context.Background(), endpoints, options)
+	assert.NoError(t, err)
+	chainId, err := client.ChainID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), chainId.Uint64())
+}
+
+type testEthService struct{}
+
+func (testEthService) ChainId() hexutil.Uint64 {
+	return 1
+}
+
+// go test -count=1 -v met/utils -run 'TestDialRpcsWebsocket'
+func TestDialRpcsWebsocket(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	assert.NoError(t, server.RegisterName("eth", testEthService{}))
+
+	ws := server.WebsocketHandler([]string{"*"})
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ws.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	wsUrl := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	// ws被选为主rpc时直接连接，不经过failoverTransport
+	endpoints := []RpcEndpoint{{Url: wsUrl, Weight: 1}}
+
+	_, err := DialRpcs(context.Background(), endpoints, TransportOptions{})
+	assert.Error(t, err)
+
+	client, err := DialRpcs(context.Background(), endpoints, TransportOptions{Headers: map[string]string{"X-Api-Key": "k"}})
+	assert.NoError(t, err)
+	defer client.Close()
+	chainId, err := client.ChainID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), chainId.Uint64())
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// 全节点一般只保留最近128个区块的状态
+	archiveDepth = 128
+)
+
+// RpcCheckResult 单个rpc的检查结果，Error不为空时表示rpc不可用
+type RpcCheckResult struct {
+	Url string `json:"url"`
+
+	LatencyP50 time.Duration `json:"latencyP50Ns"`
+	LatencyP90 time.Duration `json:"latencyP90Ns"`
+	LatencyP99 time.Duration `json:"latencyP99Ns"`
+
+	Head uint64 `json:"head"`
+	// 落后同一个network中最高区块的数量，由调用者计算
+	Lag uint64 `json:"lag"`
+
+	ChainId       uint64 `json:"chainId"`
+	ClientVersion string `json:"clientVersion"`
+
+	Eip1559    bool `json:"eip1559"`
+	FeeHistory bool `json:"feeHistory"`
+	Debug      bool `json:"debug"`
+	Trace      bool `json:"trace"`
+	Archive    bool `json:"archive"`
+	// 支持newHeads订阅，只有ws和ipc支持
+	Subscriptions bool `json:"subscriptions"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// CheckRpc 用samples次eth_blockNumber测量延迟，并检查rpc支持的功能
+func CheckRpc(ctx context.Context, rawUrl string, samples int, options TransportOptions) RpcCheckResult {
+	result := RpcCheckResult{Url: rawUrl}
+
+	rawUrl, err := ExpandSecret(rawUrl)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Url = RedactUrl(rawUrl)
+
+	client, err := dialRpcClient(ctx, []RpcEndpoint{{Url: rawUrl}}, 0, options)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer client.Close()
+
+	var latencies []time.Duration
+	for i := 0; i < samples; i++ {
+		var head hexutil.Uint64
+		start := time.Now()
+		err = client.CallContext(ctx, &head, "eth_blockNumber")
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		latencies = append(latencies, time.Since(start))
+		result.Head = max(result.Head, uint64(head))
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.LatencyP50 = percentile(latencies, 50)
+	result.LatencyP90 = percentile(latencies, 90)
+	result.LatencyP99 = percentile(latencies, 99)
+
+	var chainId hexutil.Big
+	if err = client.CallContext(ctx, &chainId, "eth_chainId"); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ChainId = (*big.Int)(&chainId).Uint64()
+
+	// 不是所有rpc都支持web3_clientVersion，失败时忽略
+	_ = client.CallContext(ctx, &result.ClientVersion, "web3_clientVersion")
+
+	var header *types.Header
+	if err = client.CallContext(ctx, &header, "eth_getBlockByNumber", "latest", false); err == nil && header != nil {
+		result.Eip1559 = header.BaseFee != nil
+	}
+
+	var feeHistory any
+	result.FeeHistory = client.CallContext(ctx, &feeHistory, "eth_feeHistory", hexutil.Uint64(1), "latest", []float64{}) == nil
+
+	// 不带参数调用，返回参数错误说明方法存在
+	result.Debug = methodAvailable(client.CallContext(ctx, nil, "debug_getRawHeader"))
+	result.Trace = methodAvailable(client.CallContext(ctx, nil, "trace_block"))
+
+	if result.Head > archiveDepth {
+		var balance hexutil.Big
+		old := hexutil.EncodeUint64(1)
+		result.Archive = client.CallContext(ctx, &balance, "eth_getBalance", common.Address{}, old) == nil
+	} else {
+		result.Archive = true
+	}
+
+	if !isHttpUrl(rawUrl) {
+		headers := make(chan *types.Header, 1)
+		if sub, err := client.EthSubscribe(ctx, headers, "newHeads"); err == nil {
+			result.Subscriptions = true
+			sub.Unsubscribe()
+		}
+	}
+
+	return result
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := (len(sorted)*p+99)/100 - 1
+	return sorted[max(index, 0)]
+}
+
+// methodAvailable 调用方法返回的错误不是method not found时认为方法可用
+func methodAvailable(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"does not exist", "not found", "not available", "not supported", "unsupported", "not allowed", "disabled"} {
+		if strings.Contains(msg, s) {
+			return false
+		}
+	}
+	// 传输错误(http 4xx 5xx等)也认为不可用
+	var httpErr rpc.HTTPError
+	return !errors.As(err, &httpErr)
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 整个值从文件读取，eg: file:~/.secrets/infura_url
+	FileRefPrefix = "file:"
+)
+
+var (
+	// ${NAME} 环境变量, ${file:/path} 文件内容, {NAME} 环境变量(network presets的写法)
+	secretRefRegexp = regexp.MustCompile(`\$\{([^}]+)\}|\{([A-Z][A-Z0-9_]*)\}`)
+	// url path中像密钥的部分，eg: infura的project id(hex)，alchemy的api key(base64url)
+	keySegmentRegexp = regexp.MustCompile(`^[A-Za-z0-9_+=-]{16,}$`)
+	digitRegexp      = regexp.MustCompile(`[0-9]`)
+	// 显示时引用先替换为占位符，避免${file:/path}中的/影响url的拆分
+	refPlaceholderRegexp = regexp.MustCompile("\x00[0-9]+\x00")
+)
+
+// HasSecretRef 判断值中是否包含环境变量或者文件引用
+func HasSecretRef(s string) bool {
+	return strings.HasPrefix(s, FileRefPrefix) || secretRefRegexp.MatchString(s)
+}
+
+// ExpandSecret 解析值中的引用，用于rpc url和header，只在dial时解析，不保存到数据库
+//
+//	${NAME} {NAME}  环境变量NAME
+//	${file:/path}   文件内容(去掉首尾空白)
+//	file:/path      整个值为文件内容
+func ExpandSecret(s string) (string, error) {
+	if strings.HasPrefix(s, FileRefPrefix) {
+		return readSecretFile(strings.TrimPrefix(s, FileRefPrefix))
+	}
+
+	var errs []string
+	expanded := secretRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		match := secretRefRegexp.FindStringSubmatch(ref)
+		name := match[1] + match[2]
+
+		if path, ok := strings.CutPrefix(name, FileRefPrefix); ok {
+			value, err := readSecretFile(path)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			return value
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			errs = append(errs, fmt.Sprintf("missing env: %v", name))
+		}
+		return value
+	})
+	if len(errs) > 0 {
+		return "", fmt.Errorf("resolve: %v error: %v", s, strings.Join(errs, ", "))
+	}
+	return expanded, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	path = ExpandHome(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read secret file: %v error: %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// RedactUrl 去掉url中的用户名密码、path和query，用于日志
+func RedactUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Host == "" {
+		return rawUrl
+	}
+	if u.User == nil && u.RawQuery == "" && (u.Path == "" || u.Path == "/") {
+		return rawUrl
+	}
+	return fmt.Sprintf("%s://%s/***", u.Scheme, u.Host)
+}
+
+// HasLiteralSecret 去掉引用之后，url的用户名密码、query或者path中是否还有可能是密钥的明文
+// 没有密钥的公共rpc(eg: https://rpc.ankr.com/eth)返回false
+func HasLiteralSecret(rawUrl string) bool {
+	u, err := url.Parse(secretRefRegexp.ReplaceAllString(rawUrl, ""))
+	if err != nil {
+		return true
+	}
+	if u.User != nil {
+		if password, _ := u.User.Password(); u.User.Username() != "" || password != "" {
+			return true
+		}
+	}
+	for _, values := range u.Query() {
+		for _, value := range values {
+			if value != "" {
+				return true
+			}
+		}
+	}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if looksLikeKey(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikeKey(s string) bool {
+	return keySegmentRegexp.MatchString(s) && digitRegexp.MatchString(s)
+}
+
+// DisplayRpcUrl 用于显示保存的rpc url，没有引用时隐藏path和query
+// 包含引用时原样显示引用，隐藏明文的用户名密码、query的值和path中像密钥的部分
+func DisplayRpcUrl(rawUrl string) string {
+	if strings.HasPrefix(rawUrl, FileRefPrefix) {
+		return rawUrl
+	}
+	if !HasSecretRef(rawUrl) {
+		return RedactUrl(rawUrl)
+	}
+
+	s, refs := replaceRefs(rawUrl)
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok {
+		return restoreRefs(redactLiterals(s, nil), refs)
+	}
+
+	end := strings.IndexAny(rest, "/?")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority, rest := rest[:end], rest[end:]
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		userinfo := strings.Split(authority[:at], ":")
+		for i := range userinfo {
+			userinfo[i] = redactLiterals(userinfo[i], nil)
+		}
+		authority = strings.Join(userinfo, ":") + authority[at:]
+	}
+
+	path, query, hasQuery := strings.Cut(rest, "?")
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = redactLiterals(segments[i], looksLikeKey)
+	}
+	display := scheme + "://" + authority + strings.Join(segments, "/")
+	if hasQuery {
+		params := strings.Split(query, "&")
+		for i, param := range params {
+			if key, value, ok := strings.Cut(param, "="); ok {
+				params[i] = key + "=" + redactLiterals(value, nil)
+			}
+		}
+		display += "?" + strings.Join(params, "&")
+	}
+	return restoreRefs(display, refs)
+}
+
+// RedactSecret 用于显示header等值，没有引用时只保留前4个字符，包含引用时只显示引用
+func RedactSecret(s string) string {
+	if strings.HasPrefix(s, FileRefPrefix) {
+		return s
+	}
+	if HasSecretRef(s) {
+		s, refs := replaceRefs(s)
+		return restoreRefs(redactLiterals(s, nil), refs)
+	}
+	if len(s) <= 8 {
+		return "***"
+	}
+	return s[:4] + "***"
+}
+
+func replaceRefs(s string) (string, []string) {
+	var refs []string
+	s = secretRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		refs = append(refs, ref)
+		return fmt.Sprintf("\x00%d\x00", len(refs)-1)
+	})
+	return s, refs
+}
+
+func restoreRefs(s string, refs []string) string {
+	return refPlaceholderRegexp.ReplaceAllStringFunc(s, func(placeholder string) string {
+		// 占位符由replaceRefs生成
+		i, _ := strconv.Atoi(strings.Trim(placeholder, "\x00"))
+		return refs[i]
+	})
+}
+
+// redactLiterals 保留引用的占位符，其他非空的部分替换为***，redact不为nil时只替换它返回true的部分
+func redactLiterals(s string, redact func(literal string) bool) string {
+	var b strings.Builder
+	last := 0
+	write := func(literal string) {
+		if literal != "" && (redact == nil || redact(literal)) {
+			literal = "***"
+		}
+		b.WriteString(literal)
+	}
+	for _, m := range refPlaceholderRegexp.FindAllStringIndex(s, -1) {
+		write(s[last:m[0]])
+		b.WriteString(s[m[0]:m[1]])
+		last = m[1]
+	}
+	write(s[last:])
+	return b.String()
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseTime 解析命令行中的时间，没有时区时使用本地时区，eg: 2024-01-02 2024-01-02T15:04:05Z
+func ParseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %v", s)
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TransportOptions network的http传输设置，用于ethclient
+// Headers BearerToken BasicAuth Proxy 支持${NAME} ${file:/path} file:/path引用，dial时解析
+type TransportOptions struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	// Authorization: Bearer <token>
+	BearerToken string `json:"bearerToken,omitempty"`
+	// user:password
+	BasicAuth string `json:"basicAuth,omitempty"`
+
+	// mTLS客户端证书和私钥，PEM文件路径
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// 自定义CA证书，PEM文件路径
+	CaCert string `json:"caCert,omitempty"`
+
+	// http:// https:// socks5://
+	Proxy string `json:"proxy,omitempty"`
+}
+
+func (o TransportOptions) IsZero() bool {
+	return len(o.Headers) == 0 && o.BearerToken == "" && o.BasicAuth == "" &&
+		o.ClientCert == "" && o.ClientKey == "" && o.CaCert == "" && o.Proxy == ""
+}
+
+// HttpHeaders 解析引用后的header，包含Authorization
+func (o TransportOptions) HttpHeaders() (http.Header, error) {
+	headers := make(http.Header)
+	for k, v := range o.Headers {
+		value, err := ExpandSecret(v)
+		if err != nil {
+			return nil, fmt.Errorf("header: %v %w", k, err)
+		}
+		headers.Set(k, value)
+	}
+
+	if o.BearerToken != "" {
+		token, err := ExpandSecret(o.BearerToken)
+		if err != nil {
+			return nil, fmt.Errorf("bearer token %w", err)
+		}
+		headers.Set("Authorization", "Bearer "+token)
+	}
+
+	if o.BasicAuth != "" {
+		auth, err := ExpandSecret(o.BasicAuth)
+		if err != nil {
+			return nil, fmt.Errorf("basic auth %w", err)
+		}
+		if !strings.Contains(auth, ":") {
+			return nil, fmt.Errorf("basic auth must be user:password")
+		}
+		headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	}
+
+	return headers, nil
+}
+
+// HttpTransport 返回设置了TLS和代理的http.Transport
+func (o TransportOptions) HttpTransport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.ClientCert != "" || o.ClientKey != "" || o.CaCert != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if o.ClientCert != "" || o.ClientKey != "" {
+			if o.ClientCert == "" || o.ClientKey == "" {
+				return nil, fmt.Errorf("client cert and client key must be set together")
+			}
+			cert, err := tls.LoadX509KeyPair(ExpandHome(o.ClientCert), ExpandHome(o.ClientKey))
+			if err != nil {
+				return nil, fmt.Errorf("load client cert error: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		if o.CaCert != "" {
+			pem, err := os.ReadFile(ExpandHome(o.CaCert))
+			if err != nil {
+				return nil, fmt.Errorf("read ca cert error: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in ca cert: %v", o.CaCert)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if o.Proxy != "" {
+		proxy, err := ExpandSecret(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy %w", err)
+		}
+		proxyUrl, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %v", RedactUrl(proxy))
+		}
+		switch proxyUrl.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme: %v", proxyUrl.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	return transport, nil
+}
+
+// Summary 用于显示，隐藏密钥
+func (o TransportOptions) Summary() []string {
+	var lines []string
+	for k, v := range o.Headers {
+		lines = append(lines, fmt.Sprintf("Header: %s: %s", k, RedactSecret(v)))
+	}
+	if o.BearerToken != "" {
+		lines = append(lines, fmt.Sprintf("Bearer Token: %s", RedactSecret(o.BearerToken)))
+	}
+	if o.BasicAuth != "" {
+		lines = append(lines, fmt.Sprintf("Basic Auth: %s", RedactSecret(o.BasicAuth)))
+	}
+	if o.ClientCert != "" {
+		lines = append(lines, fmt.Sprintf("Client Cert: %s Key: %s", o.ClientCert, o.ClientKey))
+	}
+	if o.CaCert != "" {
+		lines = append(lines, fmt.Sprintf("CA Cert: %s", o.CaCert))
+	}
+	if o.Proxy != "" {
+		lines = append(lines, fmt.Sprintf("Proxy: %s", DisplayRpcUrl(o.Proxy)))
+	}
+	return lines
+}
+
+// ExpandHome 把开头的~/替换为用户目录
+func ExpandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}